@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gayansanjeewa/gogeturl/internal/utils"
+)
+
+// runAnalyze analyzes a single URL without starting the server and prints the report.
+// The exit code is exitFetchFailed if the page could not be fetched and exitBrokenLinks if any link is broken.
+func runAnalyze(args []string) int {
+	flags := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	timeout := flags.Duration("timeout", 2*time.Minute, "maximum time for the whole analysis")
+	settings := addAnalyzerFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: gogeturl analyze [flags] <url>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitError
+	}
+
+	// Keep stdout clean for the report; only warnings and errors go to stderr
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	targetURL := flags.Arg(0)
+	if err := utils.ValidateURL(targetURL); err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid URL:", err)
+		return exitError
+	}
+
+	// Abandon the analysis on Ctrl+C or once the timeout expires
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	analyser, err := settings.build()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	defer analyser.Cache.Close()
+
+	report, fetchErr := analyser.Analyze(ctx, targetURL)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to encode report:", err)
+			return exitError
+		}
+	} else if fetchErr == nil {
+		printReport(os.Stdout, report)
+	}
+
+	switch {
+	case fetchErr != nil:
+		fmt.Fprintln(os.Stderr, "Unable to fetch the provided URL. Reason:", fetchErr)
+		return exitFetchFailed
+	case report.Links.Broken > 0:
+		return exitBrokenLinks
+	default:
+		return exitOK
+	}
+}
+
+// analyzerFlags are the flags of every command that analyzes pages from the command line.
+type analyzerFlags struct {
+	maxBody         *string
+	resources       *bool
+	hostConcurrency *string
+	hostRPS         *string
+	retries         *string
+	cacheFile       *string
+	cacheTTL        *string
+	userAgent       *string
+	respectRobots   *string
+	allow           *string
+}
+
+// addAnalyzerFlags registers the analyzer flags on flags, defaulting to the environment where the server reads it.
+func addAnalyzerFlags(flags *flag.FlagSet) *analyzerFlags {
+	return &analyzerFlags{
+		maxBody:         flags.String("max-body", os.Getenv("MAX_BODY_SIZE"), "maximum number of bytes of the page to analyze (default 10485760)"),
+		resources:       flags.Bool("resources", true, "also check images, scripts, stylesheets and other subresources"),
+		hostConcurrency: flags.String("host-concurrency", os.Getenv("HOST_CONCURRENCY"), "maximum concurrent link checks per host (0 for no cap, default 2)"),
+		hostRPS:         flags.String("host-rps", os.Getenv("HOST_RPS"), "maximum link checks started per second per host (0 for no limit)"),
+		retries:         flags.String("retries", os.Getenv("LINK_RETRIES"), "times to retry a link after a timeout, rate limiting or server error (default 2)"),
+		cacheFile:       flags.String("cache-file", os.Getenv("LINK_CACHE_FILE"), "file that keeps link check results between runs"),
+		cacheTTL:        flags.String("cache-ttl", os.Getenv("LINK_CACHE_TTL"), "how long link check results are reused (0 to disable, default 10m)"),
+		userAgent:       flags.String("user-agent", os.Getenv("USER_AGENT"), "User-Agent sent with every request and looked up in robots.txt (default "+analyzer.DefaultUserAgent+")"),
+		respectRobots:   flags.String("respect-robots", os.Getenv("RESPECT_ROBOTS"), "skip links robots.txt disallows and keep to its Crawl-delay (true or false, default false)"),
+		allow:           flags.String("allow", os.Getenv("ALLOWED_HOSTS"), "comma-separated internal hosts, IPs or CIDRs that may be fetched"),
+	}
+}
+
+// build creates the analyzer described by the parsed flags. Its Cache must be closed once the command is done.
+func (settings *analyzerFlags) build() (*analyzer.DefaultAnalyzer, error) {
+	client, err := newGuardedClient(*settings.allow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowlist: %w", err)
+	}
+	maxBodySize, err := parseByteSize(*settings.maxBody, analyzer.DefaultMaxBodySize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -max-body: %w", err)
+	}
+
+	linkChecks, err := parseLinkCheckSettings(*settings.hostConcurrency, *settings.hostRPS, *settings.retries)
+	if err != nil {
+		return nil, fmt.Errorf("invalid link check settings: %w", err)
+	}
+	if linkChecks.cache, err = newLinkCache(*settings.cacheFile, *settings.cacheTTL); err != nil {
+		return nil, fmt.Errorf("invalid link cache: %w", err)
+	}
+	linkChecks.userAgent = *settings.userAgent
+	if linkChecks.respectRobots, err = parseSwitch(*settings.respectRobots); err != nil {
+		return nil, fmt.Errorf("invalid -respect-robots: %w", err)
+	}
+
+	analyser := newAnalyzer(client, maxBodySize, linkChecks).(*analyzer.DefaultAnalyzer)
+	analyser.CheckResources = *settings.resources
+	return analyser, nil
+}
+
+// printReport writes a human-readable summary of the report followed by tables of mixed content
+// and of broken links and resources.
+func printReport(out io.Writer, report *analyzer.AnalysisReport) {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(writer, "URL\t%s\n", report.URL)
+	for _, hop := range report.Redirects.Hops {
+		downgrade := ""
+		if hop.Downgrade {
+			downgrade = " (HTTPS downgrade)"
+		}
+		fmt.Fprintf(writer, "Redirect %d\t%s%s\n", hop.StatusCode, hop.Location, downgrade)
+	}
+	if len(report.Redirects.Hops) > 0 {
+		fmt.Fprintf(writer, "Final URL\t%s\n", report.FinalURL)
+	}
+	fmt.Fprintf(writer, "Status\t%d (%s)\n", report.Response.StatusCode, report.Response.Protocol)
+	if report.Response.Server != "" {
+		fmt.Fprintf(writer, "Server\t%s\n", report.Response.Server)
+	}
+	if report.Response.ContentEncoding != "" {
+		fmt.Fprintf(writer, "Content Encoding\t%s\n", report.Response.ContentEncoding)
+	}
+	timing := report.Response.Timing
+	fmt.Fprintf(writer, "Timing\tdns %s, connect %s, tls %s, ttfb %s, download %s, total %s\n",
+		timing.DNS, timing.Connect, timing.TLS, timing.TTFB, timing.Download, timing.Total)
+	if leaf := report.TLS.Leaf(); leaf != nil {
+		fmt.Fprintf(writer, "TLS\t%s, %s, verified: %s\n", report.TLS.Version, report.TLS.CipherSuite, yesNo(report.TLS.Verified))
+		fmt.Fprintf(writer, "Certificate\t%s, issued by %s\n", leaf.Subject, leaf.Issuer)
+		fmt.Fprintf(writer, "Certificate Expires\t%s (%d days)\n", leaf.NotAfter.Format(time.DateOnly), leaf.DaysToExpiry)
+		for _, warning := range report.TLS.Warnings {
+			fmt.Fprintf(writer, "Certificate Warning\t%s\n", warning)
+		}
+	}
+	fmt.Fprintf(writer, "Title\t%s\n", report.Title)
+	fmt.Fprintf(writer, "HTML Version\t%s\n", report.HTMLVersion)
+	fmt.Fprintf(writer, "Content Type\t%s\n", report.Page.ContentType)
+	fmt.Fprintf(writer, "Encoding\t%s\n", report.Page.Encoding)
+	if report.Page.Truncated {
+		fmt.Fprintf(writer, "Page Size\t%d bytes (truncated)\n", report.Page.Size)
+	} else {
+		fmt.Fprintf(writer, "Page Size\t%d bytes\n", report.Page.Size)
+	}
+
+	for _, level := range sortedKeys(report.Headings) {
+		fmt.Fprintf(writer, "Headings %s\t%d\n", level, report.Headings[level])
+	}
+
+	fmt.Fprintf(writer, "Internal Links\t%d\n", report.Links.Internal)
+	fmt.Fprintf(writer, "External Links\t%d\n", report.Links.External)
+	fmt.Fprintf(writer, "Broken Links\t%d\n", report.Links.Broken)
+	for _, outcome := range sortedKeys(report.Links.Outcomes) {
+		fmt.Fprintf(writer, "Links (%s)\t%d\n", outcome, report.Links.Outcomes[outcome])
+	}
+	fmt.Fprintf(writer, "Link Cache\t%d hits, %d checked, %d duplicates\n", report.LinkCache.Hits, report.LinkCache.Misses, report.LinkCache.Duplicates)
+	for _, kind := range sortedKeys(report.Resources.Counts) {
+		count := report.Resources.Counts[kind]
+		if report.Resources.Checked {
+			fmt.Fprintf(writer, "Resources (%s)\t%d, %d broken\n", kind, count.Total, count.Broken)
+		} else {
+			fmt.Fprintf(writer, "Resources (%s)\t%d\n", kind, count.Total)
+		}
+	}
+	fmt.Fprintf(writer, "Login Form\t%s\n", yesNo(report.HasLoginForm))
+	if report.Security.UnprotectedLogin {
+		fmt.Fprintf(writer, "Unprotected Login\tYes (no HSTS or no CSP)\n")
+	}
+	if report.Robots != nil {
+		switch {
+		case report.Robots.StatusCode >= 500:
+			fmt.Fprintf(writer, "Robots.txt\t%s (%s, everything disallowed)\n", report.Robots.URL, report.Robots.Error)
+		case report.Robots.Error != "":
+			fmt.Fprintf(writer, "Robots.txt\t%s (%s, everything allowed)\n", report.Robots.URL, report.Robots.Error)
+		default:
+			fmt.Fprintf(writer, "Robots.txt\t%s (%d)\n", report.Robots.URL, report.Robots.StatusCode)
+		}
+		for _, crawler := range report.Robots.Crawlers {
+			access := "allowed"
+			if !crawler.Allowed {
+				access = "disallowed"
+			}
+			if crawler.CrawlDelay > 0 {
+				access += fmt.Sprintf(", crawl delay %s", crawler.CrawlDelay)
+			}
+			fmt.Fprintf(writer, "Robots (%s)\t%s\n", crawler.UserAgent, access)
+		}
+	}
+	if report.MixedContent.Checked {
+		fmt.Fprintf(writer, "Mixed Content\t%d active, %d passive\n", len(report.MixedContent.Active), len(report.MixedContent.Passive))
+	}
+	for _, check := range report.Security.Checks {
+		fmt.Fprintf(writer, "%s\t%s: %s\n", check.Name, check.Grade, check.Message)
+	}
+	for _, cookie := range report.Security.Cookies {
+		fmt.Fprintf(writer, "Cookie %s\t%s\n", cookie.Name, cookie.Grade)
+	}
+	fmt.Fprintf(writer, "Indexable\t%s\n", yesNo(report.SEO.Indexable))
+	for _, check := range report.SEO.Checks {
+		fmt.Fprintf(writer, "SEO %s\t%s: %s\n", check.Name, check.Grade, check.Message)
+	}
+	for _, alternate := range report.SEO.Alternates {
+		fmt.Fprintf(writer, "Hreflang %s\t%s\n", alternate.HrefLang, alternate.URL)
+	}
+
+	for _, section := range sortedKeys(report.Errors) {
+		fmt.Fprintf(writer, "Error (%s)\t%s\n", section, report.Errors[section])
+	}
+	_ = writer.Flush()
+
+	if len(report.MixedContent.Active)+len(report.MixedContent.Passive) > 0 {
+		fmt.Fprintln(out)
+		writer = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "MIXED\tTAG\tURL")
+		for _, resource := range report.MixedContent.Active {
+			fmt.Fprintf(writer, "active\t%s\t%s\n", resource.Tag, resource.URL)
+		}
+		for _, resource := range report.MixedContent.Passive {
+			fmt.Fprintf(writer, "passive\t%s\t%s\n", resource.Tag, resource.URL)
+		}
+		_ = writer.Flush()
+	}
+
+	broken := make([]analyzer.LinkResult, 0, report.Links.Broken)
+	for _, result := range append(report.LinkResults, report.Resources.Results...) {
+		if result.Broken {
+			broken = append(broken, result)
+		}
+	}
+	if len(broken) == 0 {
+		return
+	}
+
+	fmt.Fprintln(out)
+	writer = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "STATUS\tOUTCOME\tTAG\tURL\tERROR")
+	for _, link := range broken {
+		status := "-"
+		if link.StatusCode != 0 {
+			status = fmt.Sprintf("%d", link.StatusCode)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", status, link.Outcome, link.Tag, link.URL, link.Error)
+	}
+	_ = writer.Flush()
+}
+
+// sortedKeys returns the keys of a map in ascending order so output is stable between runs.
+func sortedKeys[K ~string, V any](values map[K]V) []K {
+	keys := make([]K, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "Yes"
+	}
+	return "No"
+}
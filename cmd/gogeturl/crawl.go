@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gayansanjeewa/gogeturl/internal/utils"
+)
+
+// runCrawl analyzes a site by following its internal links from a start URL and prints a site report.
+// The exit code is exitFetchFailed if the start page could not be fetched and exitBrokenLinks if any
+// crawled page has a broken link.
+func runCrawl(args []string) int {
+	flags := flag.NewFlagSet("crawl", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the site report as JSON")
+	timeout := flags.Duration("timeout", 10*time.Minute, "maximum time for the whole crawl")
+	depth := flags.Int("depth", analyzer.DefaultCrawlDepth, "how many links away from the start page to follow")
+	maxPages := flags.Int("max-pages", analyzer.DefaultCrawlPages, "maximum number of pages to analyze")
+	concurrency := flags.Int("concurrency", crawlConcurrency, "maximum number of pages analyzed at once")
+	settings := addAnalyzerFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: gogeturl crawl [flags] <url>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitError
+	}
+	if *depth < 0 || *maxPages < 1 {
+		fmt.Fprintln(os.Stderr, "Invalid limits: -depth must not be negative and -max-pages must be at least 1")
+		return exitError
+	}
+
+	// Keep stdout clean for the report; only warnings and errors go to stderr
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	startURL := flags.Arg(0)
+	if err := utils.ValidateURL(startURL); err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid URL:", err)
+		return exitError
+	}
+
+	// Abandon the crawl on Ctrl+C or once the timeout expires; pages analyzed so far are still reported
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	analyser, err := settings.build()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	defer analyser.Cache.Close()
+
+	crawler := analyzer.NewCrawler(analyser, *concurrency)
+	report := crawler.Crawl(ctx, startURL, analyzer.CrawlOptions{MaxDepth: *depth, MaxPages: *maxPages})
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to encode report:", err)
+			return exitError
+		}
+	} else {
+		printSiteReport(os.Stdout, report)
+	}
+
+	switch {
+	case len(report.Pages) == 0 || report.Pages[0].Error != "":
+		reason := "not an HTML page"
+		if len(report.Pages) > 0 {
+			reason = report.Pages[0].Error
+		}
+		fmt.Fprintln(os.Stderr, "Unable to fetch the provided URL. Reason:", reason)
+		return exitFetchFailed
+	case report.Totals.BrokenLinks > 0:
+		return exitBrokenLinks
+	default:
+		return exitOK
+	}
+}
+
+// printSiteReport writes the totals of a crawl followed by tables of its pages, broken links and issues.
+func printSiteReport(out io.Writer, report *analyzer.SiteReport) {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Start URL\t%s\n", report.StartURL)
+	fmt.Fprintf(writer, "Pages\t%d analyzed, %d failed\n", report.Totals.Pages, report.Totals.Failed)
+	if report.Truncated {
+		fmt.Fprintf(writer, "Truncated\tYes (page limit reached)\n")
+	}
+	fmt.Fprintf(writer, "Broken Links\t%d\n", report.Totals.BrokenLinks)
+	fmt.Fprintf(writer, "Login Pages\t%d\n", report.Totals.LoginPages)
+	fmt.Fprintf(writer, "Issues\t%d\n", report.Totals.Issues)
+	for _, nonHTML := range report.NonHTML {
+		fmt.Fprintf(writer, "Not HTML\t%s\n", nonHTML)
+	}
+	for _, disallowed := range report.Disallowed {
+		fmt.Fprintf(writer, "Disallowed by robots.txt\t%s\n", disallowed)
+	}
+	_ = writer.Flush()
+
+	fmt.Fprintln(out)
+	writer = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "DEPTH\tURL\tTITLE\tLINKS\tBROKEN\tLOGIN\tERROR")
+	for _, page := range report.Pages {
+		if page.Report == nil || page.Error != "" {
+			fmt.Fprintf(writer, "%d\t%s\t-\t-\t-\t-\t%s\n", page.Depth, page.URL, page.Error)
+			continue
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%d\t%d\t%s\t\n", page.Depth, page.URL, page.Report.Title,
+			len(page.Report.LinkResults), page.Report.Links.Broken, yesNo(page.Report.HasLoginForm))
+	}
+	_ = writer.Flush()
+
+	if len(report.BrokenLinks) > 0 {
+		fmt.Fprintln(out)
+		writer = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "STATUS\tOUTCOME\tURL\tFOUND ON")
+		for _, link := range report.BrokenLinks {
+			status := "-"
+			if link.StatusCode != 0 {
+				status = fmt.Sprintf("%d", link.StatusCode)
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", status, link.Outcome, link.URL, strings.Join(link.Referrers, ", "))
+		}
+		_ = writer.Flush()
+	}
+
+	if len(report.Issues) > 0 {
+		fmt.Fprintln(out)
+		writer = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "ISSUE\tDETAIL\tPAGES")
+		for _, issue := range report.Issues {
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", issue.Kind, issue.Detail, strings.Join(issue.Pages, ", "))
+		}
+		_ = writer.Flush()
+	}
+}
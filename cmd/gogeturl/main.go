@@ -2,61 +2,47 @@ package main
 
 import (
 	"fmt"
-	"log/slog"
-	"net/http"
 	"os"
-	"path/filepath"
-
-	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
-	"github.com/gayansanjeewa/gogeturl/internal/handler"
-
-	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 )
 
+// Process exit codes shared by all subcommands.
 const (
-	defaultPort = 8080 // Will be overwritten by .env
+	exitOK          = 0
+	exitError       = 1 // invalid usage or an unexpected failure
+	exitFetchFailed = 2
+	exitBrokenLinks = 3
 )
 
-func main() {
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	slog.SetDefault(logger)
+const usage = `Usage:
+  gogeturl [serve]                      Start the web server (default)
+  gogeturl analyze [flags] <url>        Analyze a single URL and print the report
+  gogeturl crawl [flags] <url>          Crawl a site from a URL and print the site report
+  gogeturl sitemap [flags] <url>        Analyze the pages listed in the sitemaps of a site
+  gogeturl help                         Show this help
 
-	// Load .env file
-	if err := godotenv.Load(); err != nil {
-		slog.Warn("No .env file found, using default port")
-	}
+Run "gogeturl <command> -h" for the flags of a command.
+`
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = fmt.Sprintf("%d", defaultPort)
+func main() {
+	command := "serve"
+	args := os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
 	}
 
-	gin.SetMode(gin.ReleaseMode)
-
-	router := gin.Default()
-	router.Static("/static", "./static")
-
-	router.Use(func(context *gin.Context) {
-		slog.Info("Incoming request", "method", context.Request.Method, "path", context.Request.URL.Path)
-		context.Next()
-	})
-
-	path, _ := filepath.Abs("./cmd/templates/*")
-	router.LoadHTMLGlob(path)
-
-	slog.Info(fmt.Sprintf("Starting the server at: http://localhost:%s", port))
-
-	analyser := analyzer.NewAnalyzer(nil)
-	router.POST("/analyze", handler.AnalyzeHandler(analyser)) // TODO: Fix bug - upon POSTing form navigate to /analyze route
-
-	router.GET("/", func(context *gin.Context) {
-		slog.Info("Rendering index template")
-		context.HTML(http.StatusOK, "index.html", nil)
-	})
-
-	if err := router.Run(":" + port); err != nil {
-		slog.Error("Failed to start server", "error", err)
-		os.Exit(1)
+	switch command {
+	case "serve":
+		os.Exit(runServe(args))
+	case "analyze":
+		os.Exit(runAnalyze(args))
+	case "crawl":
+		os.Exit(runCrawl(args))
+	case "sitemap":
+		os.Exit(runSitemap(args))
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", command, usage)
+		os.Exit(exitError)
 	}
 }
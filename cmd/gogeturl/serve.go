@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gayansanjeewa/gogeturl/internal/handler"
+	"github.com/gayansanjeewa/gogeturl/internal/jobs"
+	"github.com/gayansanjeewa/gogeturl/internal/netguard"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+)
+
+const (
+	defaultPort = 8080 // Will be overwritten by .env
+
+	httpClientTimeout = 10 * time.Second
+
+	jobWorkers   = 4
+	jobQueueSize = 100
+
+	// batchConcurrency caps the number of pages analyzed at once across all batch requests
+	batchConcurrency = 8
+	// crawlConcurrency caps the number of pages analyzed at once across all crawls
+	crawlConcurrency = 4
+)
+
+// newGuardedClient builds the HTTP client used for every fetch and link check. It refuses private,
+// loopback, link-local and metadata addresses unless they match the comma-separated allowlist.
+func newGuardedClient(allowlist string) (*http.Client, error) {
+	guard, err := netguard.NewGuard(netguard.ParseAllowlist(allowlist))
+	if err != nil {
+		return nil, err
+	}
+	return netguard.NewHTTPClient(guard, httpClientTimeout), nil
+}
+
+// linkCheckSettings control how politely and how persistently links are checked.
+type linkCheckSettings struct {
+	hostLimits analyzer.HostLimits
+	retries    int
+	cache      *analyzer.LinkCache
+	// userAgent replaces analyzer.DefaultUserAgent when set
+	userAgent     string
+	respectRobots bool
+}
+
+// newAnalyzer creates the analyzer shared by every route, reading at most maxBodySize bytes per page
+// and checking links with the given settings.
+func newAnalyzer(client *http.Client, maxBodySize int64, settings linkCheckSettings) analyzer.Analyzer {
+	analyser := analyzer.NewAnalyzer(client).(*analyzer.DefaultAnalyzer)
+	analyser.MaxBodySize = maxBodySize
+	analyser.Hosts = analyzer.NewHostLimiter(settings.hostLimits)
+	analyser.Retries = settings.retries
+	analyser.Cache = settings.cache
+	if settings.userAgent != "" {
+		analyser.UserAgent = settings.userAgent
+	}
+	analyser.RespectRobots = settings.respectRobots
+	return analyser
+}
+
+// parseByteSize parses a size in bytes, falling back to fallback when value is empty.
+func parseByteSize(value string, fallback int64) (int64, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("%q is not a valid size in bytes", value)
+	}
+	return size, nil
+}
+
+// parseLinkCheckSettings parses the per-host concurrency cap, the requests per second per host and
+// the number of retries, falling back to the analyzer defaults when a value is empty.
+func parseLinkCheckSettings(concurrency, requestsPerSecond, retries string) (linkCheckSettings, error) {
+	settings := linkCheckSettings{
+		hostLimits: analyzer.HostLimits{Concurrency: analyzer.DefaultHostConcurrency, MaxRetryAfter: analyzer.DefaultMaxRetryAfter, MaxCrawlDelay: analyzer.DefaultMaxCrawlDelay},
+		retries:    analyzer.DefaultRetries,
+	}
+	if concurrency != "" {
+		value, err := strconv.Atoi(concurrency)
+		if err != nil || value < 0 {
+			return settings, fmt.Errorf("%q is not a valid number of concurrent requests", concurrency)
+		}
+		settings.hostLimits.Concurrency = value
+	}
+	if requestsPerSecond != "" {
+		value, err := strconv.ParseFloat(requestsPerSecond, 64)
+		if err != nil || value < 0 {
+			return settings, fmt.Errorf("%q is not a valid number of requests per second", requestsPerSecond)
+		}
+		settings.hostLimits.RequestsPerSecond = value
+	}
+	if retries != "" {
+		value, err := strconv.Atoi(retries)
+		if err != nil || value < 0 {
+			return settings, fmt.Errorf("%q is not a valid number of retries", retries)
+		}
+		settings.retries = value
+	}
+	return settings, nil
+}
+
+// parseSwitch parses an on/off setting such as "true" or "0", which is off when value is empty.
+func parseSwitch(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%q is neither true nor false", value)
+	}
+	return enabled, nil
+}
+
+// newLinkCache creates the cache of link check results, kept for ttl (DefaultCacheTTL when empty) and,
+// if path is set, also in that file so it survives restarts. A ttl of zero disables the cache.
+func newLinkCache(path, ttl string) (*analyzer.LinkCache, error) {
+	duration := analyzer.DefaultCacheTTL
+	if ttl != "" {
+		var err error
+		if duration, err = time.ParseDuration(ttl); err != nil || duration < 0 {
+			return nil, fmt.Errorf("%q is not a valid cache duration", ttl)
+		}
+	}
+	switch {
+	case duration == 0:
+		return nil, nil
+	case path != "":
+		return analyzer.OpenLinkCache(path, duration)
+	}
+	return analyzer.NewLinkCache(duration), nil
+}
+
+// runServe starts the Gin web server and blocks until it stops.
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
+	// Load .env file
+	if err := godotenv.Load(); err != nil {
+		slog.Warn("No .env file found, using default port")
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = fmt.Sprintf("%d", defaultPort)
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+
+	router := gin.Default()
+	router.Static("/static", "./static")
+
+	router.Use(func(context *gin.Context) {
+		slog.Info("Incoming request", "method", context.Request.Method, "path", context.Request.URL.Path)
+		context.Next()
+	})
+
+	path, _ := filepath.Abs("./cmd/templates/*")
+	router.LoadHTMLGlob(path)
+
+	slog.Info(fmt.Sprintf("Starting the server at: http://localhost:%s", port))
+
+	client, err := newGuardedClient(os.Getenv("ALLOWED_HOSTS"))
+	if err != nil {
+		slog.Error("Invalid ALLOWED_HOSTS", "error", err)
+		return exitError
+	}
+
+	maxBodySize, err := parseByteSize(os.Getenv("MAX_BODY_SIZE"), analyzer.DefaultMaxBodySize)
+	if err != nil {
+		slog.Error("Invalid MAX_BODY_SIZE", "error", err)
+		return exitError
+	}
+
+	linkChecks, err := parseLinkCheckSettings(os.Getenv("HOST_CONCURRENCY"), os.Getenv("HOST_RPS"), os.Getenv("LINK_RETRIES"))
+	if err != nil {
+		slog.Error("Invalid HOST_CONCURRENCY, HOST_RPS or LINK_RETRIES", "error", err)
+		return exitError
+	}
+	if linkChecks.cache, err = newLinkCache(os.Getenv("LINK_CACHE_FILE"), os.Getenv("LINK_CACHE_TTL")); err != nil {
+		slog.Error("Invalid LINK_CACHE_FILE or LINK_CACHE_TTL", "error", err)
+		return exitError
+	}
+	linkChecks.userAgent = os.Getenv("USER_AGENT")
+	if linkChecks.respectRobots, err = parseSwitch(os.Getenv("RESPECT_ROBOTS")); err != nil {
+		slog.Error("Invalid RESPECT_ROBOTS", "error", err)
+		return exitError
+	}
+
+	analyser := newAnalyzer(client, maxBodySize, linkChecks)
+	router.POST("/analyze", handler.AnalyzeHandler(analyser)) // TODO: Fix bug - upon POSTing form navigate to /analyze route
+
+	batchRunner := analyzer.NewBatchRunner(analyser, batchConcurrency)
+	router.POST("/batch", handler.BatchHandler(batchRunner))
+
+	crawler := analyzer.NewCrawler(analyser, crawlConcurrency)
+	router.POST("/crawl", handler.CrawlHandler(crawler))
+
+	sitemaps := analyser.(analyzer.SitemapFinder)
+	router.POST("/sitemap", handler.SitemapHandler(sitemaps, batchRunner))
+
+	api := router.Group("/api/v1")
+	api.GET("/analyze", handler.AnalyzeAPIHandler(analyser))
+	api.POST("/analyze", handler.AnalyzeAPIHandler(analyser))
+	api.GET("/analyze/stream", handler.AnalyzeStreamHandler(analyser))
+	api.POST("/batch", handler.BatchAPIHandler(batchRunner))
+	api.POST("/crawl", handler.CrawlAPIHandler(crawler))
+	api.POST("/sitemap", handler.SitemapAPIHandler(sitemaps, batchRunner))
+
+	jobManager := jobs.NewManager(analyser, jobWorkers, jobQueueSize)
+	jobManager.Start(context.Background())
+	api.POST("/jobs", handler.SubmitJobHandler(jobManager))
+	api.GET("/jobs/:id", handler.JobStatusHandler(jobManager))
+
+	router.GET("/", func(context *gin.Context) {
+		slog.Info("Rendering index template")
+		context.HTML(http.StatusOK, "index.html", nil)
+	})
+
+	if err := router.Run(":" + port); err != nil {
+		slog.Error("Failed to start server", "error", err)
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gayansanjeewa/gogeturl/internal/utils"
+)
+
+// runSitemap analyzes the pages listed in the sitemaps of a site and prints the report. The exit code
+// is exitFetchFailed if no sitemap lists any page and exitBrokenLinks if any entry does not respond
+// directly with a 2xx status or any analyzed page has a broken link.
+func runSitemap(args []string) int {
+	flags := flag.NewFlagSet("sitemap", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the sitemap report as JSON")
+	timeout := flags.Duration("timeout", 10*time.Minute, "maximum time for the whole analysis")
+	maxURLs := flags.Int("max-urls", analyzer.DefaultSitemapURLs, "maximum number of sitemap entries to analyze")
+	concurrency := flags.Int("concurrency", batchConcurrency, "maximum number of pages analyzed at once")
+	settings := addAnalyzerFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: gogeturl sitemap [flags] <url>")
+		fmt.Fprintln(flags.Output(), "The URL is a sitemap, or any page of a site whose sitemaps are found through robots.txt or /sitemap.xml.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitError
+	}
+	if *maxURLs < 1 {
+		fmt.Fprintln(os.Stderr, "Invalid limit: -max-urls must be at least 1")
+		return exitError
+	}
+
+	// Keep stdout clean for the report; only warnings and errors go to stderr
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	siteURL := flags.Arg(0)
+	if err := utils.ValidateURL(siteURL); err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid URL:", err)
+		return exitError
+	}
+
+	// Abandon the analysis on Ctrl+C or once the timeout expires; pages analyzed so far are still reported
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	analyser, err := settings.build()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	defer analyser.Cache.Close()
+
+	runner := analyzer.NewBatchRunner(analyser, *concurrency)
+	report, err := runner.RunSitemap(ctx, analyser, siteURL, *maxURLs)
+	if err != nil {
+		// Either no sitemap could be fetched or none lists any page
+		fmt.Fprintln(os.Stderr, "Unable to read the sitemap. Reason:", err)
+		return exitFetchFailed
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to encode report:", err)
+			return exitError
+		}
+	} else {
+		printSitemapReport(os.Stdout, report)
+	}
+
+	if len(report.Issues) > 0 || report.Batch.Totals.BrokenLinks > 0 {
+		return exitBrokenLinks
+	}
+	return exitOK
+}
+
+// printSitemapReport writes the sitemaps that were read, the entries that did not respond directly
+// and a row for every analyzed page.
+func printSitemapReport(out io.Writer, report *analyzer.SitemapReport) {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, file := range report.Sitemaps {
+		switch {
+		case file.Error != "":
+			fmt.Fprintf(writer, "Sitemap\t%s\t%s\n", file.URL, file.Error)
+		case file.Index:
+			fmt.Fprintf(writer, "Sitemap Index\t%s\t%d sitemaps\n", file.URL, file.URLs)
+		default:
+			fmt.Fprintf(writer, "Sitemap\t%s\t%d URLs\n", file.URL, file.URLs)
+		}
+	}
+	totals := report.Batch.Totals
+	fmt.Fprintf(writer, "Pages\t%d analyzed, %d failed\t\n", totals.Succeeded, totals.Failed)
+	if report.Truncated {
+		fmt.Fprintf(writer, "Truncated\tYes (URL limit reached)\t\n")
+	}
+	fmt.Fprintf(writer, "Issues\t%d\t\n", len(report.Issues))
+	fmt.Fprintf(writer, "Broken Links\t%d\t\n", totals.BrokenLinks)
+	_ = writer.Flush()
+
+	if len(report.Issues) > 0 {
+		fmt.Fprintln(out)
+		writer = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "STATUS\tISSUE\tURL\tREDIRECTED TO")
+		for _, issue := range report.Issues {
+			fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", issue.StatusCode, issue.Kind, issue.URL, issue.RedirectedTo)
+		}
+		_ = writer.Flush()
+	}
+
+	fmt.Fprintln(out)
+	writer = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "URL\tTITLE\tLINKS\tBROKEN\tLOGIN\tERROR")
+	for _, result := range report.Batch.Results {
+		if result.Report == nil || result.Error != "" {
+			fmt.Fprintf(writer, "%s\t-\t-\t-\t-\t%s\n", result.URL, result.Error)
+			continue
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%s\t\n", result.URL, result.Report.Title,
+			len(result.Report.LinkResults), result.Report.Links.Broken, yesNo(result.Report.HasLoginForm))
+	}
+	_ = writer.Flush()
+}
@@ -1,15 +1,14 @@
 package analyzer
 
 import (
+	"context"
 	"errors"
-	"io"
 	"net/http"
 	"net/url"
-	"regexp"
-	"strings"
 	"sync"
 	"time"
 
+	"github.com/gayansanjeewa/gogeturl/internal/netguard"
 	"golang.org/x/net/html"
 )
 
@@ -19,192 +18,479 @@ type HTTPClient interface {
 }
 
 type Analyzer interface {
-	FetchHTML(targetURL string) (string, error)
+	Analyze(ctx context.Context, targetURL string) (*AnalysisReport, error)
+	FetchHTML(ctx context.Context, targetURL string) (*Page, error)
 	ExtractTitle(body string) string
 	CountHeadings(body string) map[string]int
-	AnalyzeLinks(body, baseURL string) (internal, external, broken int, err error)
+	AnalyzeLinks(ctx context.Context, body, baseURL string) (internal, external, broken int, err error)
 	DetectLoginForm(body string) bool
 	DetectHTMLVersion(body string) string
 }
 
 type DefaultAnalyzer struct {
 	Client HTTPClient
+	// Timeout bounds a whole call to Analyze, including every link check. Zero means no deadline.
+	Timeout time.Duration
+	// MaxBodySize is the number of bytes of a page that are read; anything beyond is dropped
+	// and the page is marked as truncated. Zero means no limit.
+	MaxBodySize int64
+	// CheckResources makes Analyze request every subresource of the inventory, such as images
+	// and scripts, alongside the links. Otherwise they are only listed.
+	CheckResources bool
+	// Hosts paces link checks per host and honors Retry-After. Nil means hosts are not limited.
+	Hosts *HostLimiter
+	// Cache keeps link check results between analyses. Nil means every analysis checks its links afresh.
+	Cache *LinkCache
+	// Retries is the number of times a link is retried after a transient failure, waiting
+	// RetryBackoff before the first retry and twice as long before each following one.
+	Retries      int
+	RetryBackoff time.Duration
+	// UserAgent is sent with every request and is the name the analyzer goes by in robots.txt.
+	UserAgent string
+	// Robots keeps the robots.txt of each site, so reports can tell which crawlers may request the page.
+	// Nil means robots.txt is neither fetched nor reported.
+	Robots *RobotsCache
+	// RespectRobots makes link checks skip URLs that robots.txt disallows for UserAgent and keep to
+	// its Crawl-delay, pages included, so crawls only follow links they are allowed to.
+	RespectRobots bool
 }
 
+// NewAnalyzer creates an Analyzer that uses client for every request.
+// A nil client defaults to one guarded against private and internal addresses, with no allowlist.
+// An *http.Client is copied so that redirects are followed, and recorded, by the analyzer itself.
 func NewAnalyzer(client HTTPClient) Analyzer {
 	if client == nil {
-		client = &http.Client{Timeout: 10 * time.Second}
+		guard, _ := netguard.NewGuard(nil)
+		client = netguard.NewHTTPClient(guard, 10*time.Second)
+	}
+	return &DefaultAnalyzer{
+		Client:         withoutRedirects(client),
+		Timeout:        defaultAnalysisTimeout,
+		MaxBodySize:    DefaultMaxBodySize,
+		CheckResources: true,
+		Hosts:          NewHostLimiter(HostLimits{Concurrency: DefaultHostConcurrency, MaxRetryAfter: DefaultMaxRetryAfter, MaxCrawlDelay: DefaultMaxCrawlDelay}),
+		Cache:          NewLinkCache(DefaultCacheTTL),
+		Retries:        DefaultRetries,
+		RetryBackoff:   DefaultRetryBackoff,
+		UserAgent:      DefaultUserAgent,
+		Robots:         NewRobotsCache(DefaultRobotsTTL),
 	}
-	return &DefaultAnalyzer{Client: client}
 }
 
-const maxWorkers = 10
+// DefaultMaxBodySize is the page size limit used by NewAnalyzer.
+const DefaultMaxBodySize = 10 << 20
+
+const (
+	maxWorkers             = 10
+	defaultAnalysisTimeout = 2 * time.Minute
+)
 
-// FetchHTML fetches the HTML content of the page and returns it as a string.
-func (analyser *DefaultAnalyzer) FetchHTML(targetURL string) (string, error) {
-	req, err := http.NewRequest("GET", targetURL, nil)
+// Analyze fetches the page at targetURL and runs every detector over it, returning a single report.
+// A non-nil error is returned only when the page itself could not be fetched; failures in
+// individual sections are recorded in the report's Errors map instead.
+func (analyser *DefaultAnalyzer) Analyze(ctx context.Context, targetURL string) (*AnalysisReport, error) {
+	report := &AnalysisReport{URL: targetURL}
+
+	if analyser.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, analyser.Timeout)
+		defer cancel()
+	}
+
+	page, err := analyser.fetchPage(ctx, targetURL)
 	if err != nil {
-		return "", err
+		var redirectErr *RedirectError
+		if errors.As(err, &redirectErr) {
+			report.Redirects = newRedirectChain(redirectErr.Hops, err)
+		}
+		var certificateErr *CertificateError
+		if errors.As(err, &certificateErr) {
+			report.TLS = certificateErr.TLS
+		}
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			report.Response.StatusCode = statusErr.StatusCode
+		}
+		report.addError(SectionFetch, err)
+		return report, err
 	}
 
-	resp, err := analyser.Client.Do(req)
+	report.FinalURL = page.URL
+	report.Response = page.Response
+	report.TLS = page.TLS
+	report.Redirects = newRedirectChain(page.Redirects, nil)
+
+	report.Page = PageInfo{ContentType: page.ContentType, Encoding: page.Encoding, Size: len(page.Body), Truncated: page.Truncated}
+	body := page.Body
+
+	doctype := &doctypeCollector{}
+	title := &titleCollector{}
+	headings := newHeadingCollector()
+	loginForm := &loginFormCollector{}
+	links := newLinkCollector()
+	resources := &resourceCollector{}
+	metadata := &seoCollector{}
+	walkDocument(body, doctype, title, headings, loginForm, links, resources, metadata)
+
+	report.HTMLVersion = doctype.result()
+	report.Title = title.title
+	report.Headings = headings.headings
+	report.HasLoginForm = loginForm.found
+	report.Security = auditSecurity(page.Response.Headers, page.URL, report.HasLoginForm)
+	report.MixedContent = findMixedContent(resources.resources, page.URL)
+	report.SEO = auditSEO(metadata, report.Title, page.Response.Headers, page.URL)
+	report.Robots = analyser.checkRobots(ctx, page.URL)
+
+	// Links and subresources share one pool of workers
+	toCheck := links.links
+	resourceLinks := inventoryLinks(resources.resources)
+	if analyser.CheckResources {
+		toCheck = append(toCheck[:len(toCheck):len(toCheck)], resourceLinks...)
+	}
+
+	progress := progressFrom(ctx)
+	progress(ProgressEvent{Type: EventFetched, Total: len(toCheck)})
+
+	results, err := analyser.checkLinks(ctx, toCheck, page.URL)
 	if err != nil {
-		return "", err
+		report.addError(SectionLinks, err)
 	}
-	defer func(Body io.ReadCloser) {
-		_ = Body.Close()
-	}(resp.Body)
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", errors.New("received non-2xx status code: " + resp.Status)
+	var resourceResults []LinkResult
+	if analyser.CheckResources {
+		if results != nil {
+			results, resourceResults = results[:len(links.links)], results[len(links.links):]
+		}
+	} else {
+		resourceResults = resolveLinks(resourceLinks, page.URL)
 	}
+	report.Links = summarizeLinks(results)
+	report.LinkResults = results
+	report.Resources = summarizeResources(resourceResults, analyser.CheckResources && err == nil)
+	report.LinkCache = summarizeCache(append(results[:len(results):len(results)], resourceResults...))
+
+	// Resources that were only listed are not counted, just as they are left out of Total
+	checked := len(results)
+	if analyser.CheckResources {
+		checked += len(resourceResults)
+	}
+	progress(ProgressEvent{Type: EventDone, Checked: checked, Total: len(toCheck), Summary: &report.Links})
+
+	return report, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+// fetchPage fetches the page to analyze. When robots.txt is respected the fetch takes a slot of its
+// host like a link check does, so the Crawl-delay of a site also holds between the pages of a crawl.
+func (analyser *DefaultAnalyzer) fetchPage(ctx context.Context, targetURL string) (*Page, error) {
+	if !analyser.RespectRobots {
+		return analyser.FetchHTML(ctx, targetURL)
+	}
+	release, err := analyser.Hosts.acquire(ctx, hostKey(targetURL))
 	if err != nil {
-		return "", errors.New("failed to read response body: " + err.Error())
+		return nil, err
 	}
+	defer release()
 
-	return string(body), nil
+	// The page was asked for, so it is fetched even if robots.txt disallows it
+	analyser.politely(ctx, targetURL)
+	return analyser.FetchHTML(ctx, targetURL)
 }
 
 // ExtractTitle returns the content of the <title> tag from the HTML body string
 func (analyser *DefaultAnalyzer) ExtractTitle(body string) string {
-	var title string
-	tokenizer := html.NewTokenizer(strings.NewReader(body))
-	for {
-		tokenType := tokenizer.Next()
-		if tokenType == html.ErrorToken {
-			break
-		}
-		if tokenType == html.StartTagToken {
-			token := tokenizer.Token()
-			if token.Data == "title" {
-				tokenType = tokenizer.Next()
-				if tokenType == html.TextToken {
-					title = tokenizer.Token().Data
-					break
-				}
-			}
-		}
-	}
-	return title
+	title := &titleCollector{}
+	walkDocument(body, title)
+	return title.title
 }
 
 // CountHeadings counts the number of headers in the HTML document, sorted by type.
 // It accepts the body of the HTML document as a string and returns a map of header types to their respective counts.
 func (analyser *DefaultAnalyzer) CountHeadings(body string) map[string]int {
-	headers := make(map[string]int)
-	headerRegex := regexp.MustCompile(`^h[1-6]$`)
+	headings := newHeadingCollector()
+	walkDocument(body, headings)
+	return headings.headings
+}
 
-	tokenizer := html.NewTokenizer(strings.NewReader(body))
-	for {
-		tokenType := tokenizer.Next()
+// AnalyzeLinks parses links from the HTML body, resolves relative URLs, handles <base> tags,
+// and counts internal, external, and broken links on the page.
+// It uses concurrency to efficiently check the accessibility of each link, and stops
+// checking as soon as ctx is cancelled, returning the context's error.
+func (analyser *DefaultAnalyzer) AnalyzeLinks(ctx context.Context, body, baseURL string) (internal, external, broken int, err error) {
+	links := newLinkCollector()
+	walkDocument(body, links)
 
-		if tokenType == html.ErrorToken {
-			break // stop at the end of the document
-		}
+	results, err := analyser.checkLinks(ctx, links.links, baseURL)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	summary := summarizeLinks(results)
+	return summary.Internal, summary.External, summary.Broken, nil
+}
 
-		if tokenType == html.StartTagToken {
-			token := tokenizer.Token()
-			if headerRegex.MatchString(token.Data) {
-				headerType := strings.ToLower(token.Data)
-				headers[headerType]++
+// checkLinks classifies the collected links against baseURL and checks each one with a pool of workers.
+// Links are queued per host, so a host held back by analyser.Hosts never keeps workers from checking
+// other hosts; at most maxWorkers checks are in flight overall, and a check waiting to retry gives up its
+// worker until it tries again. A URL that appears more than once is
+// checked once, and results in analyser.Cache are used without a request. Results are returned in document order.
+func (analyser *DefaultAnalyzer) checkLinks(ctx context.Context, links []extractedLink, baseURL string) ([]LinkResult, error) {
+	parsedBaseURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group links by the host they will be requested from, keeping the order hosts first appear in.
+	// Later copies of a URL are not queued but take the result of its first occurrence.
+	var hosts []string
+	queues := map[string][]int{}
+	firstOf := map[string]int{}
+	duplicates := map[int][]int{}
+	for index, link := range links {
+		result, ok := resolveLink(link, parsedBaseURL)
+		if ok {
+			if first, seen := firstOf[result.URL]; seen {
+				duplicates[first] = append(duplicates[first], index)
+				continue
 			}
+			firstOf[result.URL] = index
+		}
+		host := hostKey(result.URL)
+		if _, seen := queues[host]; !seen {
+			hosts = append(hosts, host)
 		}
+		queues[host] = append(queues[host], index)
 	}
 
-	return headers
-}
+	// Each worker writes only to its own index, so results need no further synchronization
+	results := make([]LinkResult, len(links))
+	workers := make(chan struct{}, maxWorkers)
+
+	// A check gives its worker back while it waits to retry, so hosts that are slow to recover
+	// never hold every worker. Taking it back cannot block for long: the holders only make requests.
+	ctx = withIdle(ctx, func(wait func() error) error {
+		<-workers
+		defer func() { workers <- struct{}{} }()
+		return wait()
+	})
+
+	// Progress is reported under a lock so listeners see a monotonic count and are never called concurrently
+	progress := progressFrom(ctx)
+	var progressMutex sync.Mutex
+	checked := 0
+
+	record := func(index int, result LinkResult) {
+		results[index] = result
+
+		progressMutex.Lock()
+		checked++
+		progress(ProgressEvent{Type: EventLinkChecked, Checked: checked, Total: len(links), Link: &result})
+		if result.Broken {
+			progress(ProgressEvent{Type: EventBrokenLink, Checked: checked, Total: len(links), Link: &result})
+		}
+		progressMutex.Unlock()
+	}
 
-// AnalyzeLinks parses links from the HTML body, resolves relative URLs, handles <base> tags,
-// and counts internal, external, and broken links on the page.
-// It uses concurrency to efficiently check the accessibility of each link.
-func (analyser *DefaultAnalyzer) AnalyzeLinks(body, baseURL string) (internal, external, broken int, err error) {
-	var baseParsed *url.URL
-	var links []string
+	check := func(host string, index int) {
+		// Skip remaining links without issuing requests once the analysis is abandoned
+		if ctx.Err() != nil {
+			return
+		}
 
-	tokenizer := html.NewTokenizer(strings.NewReader(body))
-	for {
-		tokenType := tokenizer.Next()
-		if tokenType == html.ErrorToken {
-			break
+		// Cached links take neither a slot of their host nor a worker
+		result, cached := analyser.cachedLink(ctx, links[index], parsedBaseURL)
+		if !cached {
+			release, err := analyser.Hosts.acquire(ctx, host)
+			if err != nil {
+				return
+			}
+
+			// Only take a worker once the host is ready, so waiting on one host does not block the others
+			select {
+			case workers <- struct{}{}:
+			case <-ctx.Done():
+				release()
+				return
+			}
+			result, err = analyser.checkLink(ctx, links[index], parsedBaseURL)
+			<-workers
+			release()
+			if err != nil {
+				return
+			}
 		}
-		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
-			continue
+
+		record(index, result)
+		for _, duplicate := range duplicates[index] {
+			record(duplicate, withLink(result, links[duplicate]))
+		}
+	}
+
+	// Spawn goroutines for each host, no more than it may have requests in flight
+	var waitGroup sync.WaitGroup
+	for _, host := range hosts {
+		queue := queues[host]
+		jobs := make(chan int, len(queue))
+		for _, index := range queue {
+			jobs <- index
 		}
+		close(jobs)
 
-		token := tokenizer.Token()
-		switch token.Data {
-		case "base":
-			// Parse the <base> tag to resolve relative URLs correctly
-			baseParsed = extractBaseHref(token)
-		case "a", "link":
-			links = extractLinks(token, baseParsed, links)
+		perHost := analyser.Hosts.concurrency()
+		if perHost <= 0 || perHost > maxWorkers {
+			perHost = maxWorkers
+		}
+		for i := 0; i < min(perHost, len(queue)); i++ {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				for index := range jobs {
+					check(host, index)
+				}
+			}()
 		}
 	}
 
-	parsedBaseURL, err := url.Parse(baseURL)
-	if err != nil {
-		return 0, 0, 0, err
+	waitGroup.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	type linkResult struct {
-		isInternal bool
-		isBroken   bool
+	return results, nil
+}
+
+type idleKey struct{}
+
+// withIdle returns a context that makes link checks run their waits through fn, which lets go of
+// what the caller holds for the length of a wait.
+func withIdle(ctx context.Context, fn func(wait func() error) error) context.Context {
+	return context.WithValue(ctx, idleKey{}, fn)
+}
+
+// idle runs wait through the function attached to ctx by withIdle, or directly if there is none.
+func idle(ctx context.Context, wait func() error) error {
+	if fn, ok := ctx.Value(idleKey{}).(func(wait func() error) error); ok {
+		return fn(wait)
 	}
+	return wait()
+}
 
-	// Use buffered channels for jobs and results to handle concurrency
-	jobs := make(chan string, len(links))
-	results := make(chan linkResult, len(links))
+// checkLink resolves a single link against the page URL, classifies it, and checks its accessibility.
+// It fails only when ctx is done before the link could be checked.
+func (analyser *DefaultAnalyzer) checkLink(ctx context.Context, link extractedLink, base *url.URL) (LinkResult, error) {
+	result, ok := resolveLink(link, base)
+	if !ok {
+		return result, nil
+	}
+	if !analyser.politely(ctx, result.URL) {
+		result.Outcome = OutcomeBlockedByRobots
+		result.Error = "disallowed by robots.txt"
+		return result, nil
+	}
 
-	// Spawn worker goroutines to check link accessibility
-	var waitGroup sync.WaitGroup
-	for i := 0; i < maxWorkers; i++ {
-		waitGroup.Add(1)
-		go func() {
-			defer waitGroup.Done()
-			for link := range jobs {
-				resolvedURL, err := url.Parse(link)
-				if err != nil {
-					results <- linkResult{false, true}
-					continue
-				}
+	entry, cached, err := analyser.Cache.check(ctx, result.URL, func() cacheEntry {
+		return analyser.checkURL(ctx, result.URL)
+	})
+	if err != nil {
+		return result, err
+	}
+	return entry.applyTo(result, cached), nil
+}
 
-				resolved := parsedBaseURL.ResolveReference(resolvedURL)
-				isInternal := sameHost(parsedBaseURL, resolved)
-				isBroken := analyser.checkLinkBroken(resolved.String())
-				results <- linkResult{isInternal, isBroken}
-			}
-		}()
+// cachedLink returns the result of a link from analyser.Cache, if it holds one. When robots.txt is
+// respected, a link it disallows is never answered from the cache, so checkLink can report it as blocked
+// even if it was cached by an analysis that did not respect robots.txt.
+func (analyser *DefaultAnalyzer) cachedLink(ctx context.Context, link extractedLink, base *url.URL) (LinkResult, bool) {
+	result, ok := resolveLink(link, base)
+	if !ok {
+		return result, false
+	}
+	if analyser.RespectRobots && !analyser.robots(ctx, result.URL).allows(analyser.UserAgent, result.URL) {
+		return result, false
 	}
+	entry, ok := analyser.Cache.get(result.URL)
+	if !ok {
+		return result, false
+	}
+	return entry.applyTo(result, true), true
+}
 
-	// Feed links to workers via the jobs channel
-	for _, link := range links {
-		jobs <- link
+// checkURL requests a resolved link and classifies the outcome.
+func (analyser *DefaultAnalyzer) checkURL(ctx context.Context, target string) cacheEntry {
+	check := analyser.checkLinkStatus(ctx, target)
+	entry := cacheEntry{
+		URL:        target,
+		StatusCode: check.statusCode,
+		FinalURL:   check.finalURL,
+		Attempts:   check.attempts,
+		Latency:    check.latency,
+		CheckedAt:  time.Now(),
 	}
-	close(jobs) // Close jobs channel to signal no more links
+	entry.Outcome = classifyOutcome(check.statusCode, check.finalURL != "" && check.finalURL != target, check.err)
+	if check.err != nil {
+		entry.Error = check.err.Error()
+	}
+	return entry
+}
 
-	waitGroup.Wait()
-	close(results) // Close results channel after all workers finish
-
-	// Aggregate results from workers
-	for res := range results {
-		if res.isInternal {
-			internal++
-		} else {
-			external++
-		}
-		if res.isBroken {
-			broken++
-		}
+// applyTo fills in the check of result from entry. Results taken from the cache made no attempts of their own.
+func (entry cacheEntry) applyTo(result LinkResult, cached bool) LinkResult {
+	result.StatusCode = entry.StatusCode
+	result.Outcome = entry.Outcome
+	result.Broken = entry.Outcome.Broken()
+	result.Error = entry.Error
+	result.Latency = entry.Latency
+	result.Cached = cached
+	if !cached {
+		result.Attempts = entry.Attempts
+	}
+	if entry.FinalURL != "" && entry.FinalURL != result.URL {
+		result.RedirectedTo = entry.FinalURL
+	}
+	return result
+}
+
+// withLink returns the result of checking a URL as it applies to another link to the same URL.
+func withLink(result LinkResult, link extractedLink) LinkResult {
+	result.Href, result.Tag, result.Type, result.Text = link.href, link.tag, link.kind, link.text
+	return result
+}
+
+// resolveLink resolves a link against the page URL and classifies it as internal or external.
+// A link that cannot be parsed is returned as broken with ok set to false.
+func resolveLink(link extractedLink, base *url.URL) (result LinkResult, ok bool) {
+	result = LinkResult{Href: link.href, URL: link.link, Tag: link.tag, Type: link.kind, Text: link.text}
+
+	parsed, err := url.Parse(link.link)
+	if err != nil {
+		result.Outcome = OutcomeInvalidURL
+		result.Broken = true
+		result.Error = err.Error()
+		return result, false
 	}
 
-	return internal, external, broken, nil
+	resolved := base.ResolveReference(parsed)
+	result.URL = resolved.String()
+	result.Internal = sameHost(base, resolved)
+	return result, true
+}
+
+// resolveLinks resolves and classifies links without requesting them.
+func resolveLinks(links []extractedLink, baseURL string) []LinkResult {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+	results := make([]LinkResult, len(links))
+	for index, link := range links {
+		results[index], _ = resolveLink(link, base)
+	}
+	return results
 }
 
 // extractLinks filters and extracts href attributes from <a> and <link> tags,
 // ignoring mailto:, tel:, and javascript: schemes to avoid non-http links.
-func extractLinks(token html.Token, baseParsed *url.URL, links []string) []string {
+func extractLinks(token html.Token, baseParsed *url.URL, links []extractedLink) []extractedLink {
 	for _, attr := range token.Attr {
 		if attr.Key != "href" {
 			continue
@@ -225,7 +511,7 @@ func extractLinks(token html.Token, baseParsed *url.URL, links []string) []strin
 			link = baseParsed.ResolveReference(parsed).String()
 		}
 
-		links = append(links, link)
+		links = append(links, extractedLink{href: attr.Val, link: link, tag: token.Data})
 	}
 	return links
 }
@@ -242,74 +528,88 @@ func extractBaseHref(token html.Token) *url.URL {
 	return nil
 }
 
-// checkLinkBroken sends a HEAD request (or fallback GET) and returns whether the link is broken.
-func (analyser *DefaultAnalyzer) checkLinkBroken(link string) bool {
-	// Try HEAD request to check link quickly without downloading the body
-	req, err := http.NewRequest("HEAD", link, nil)
-	if err != nil {
-		return true
+// linkCheck is the last attempt at requesting a link. A non-nil err means the link could not be reached at all.
+type linkCheck struct {
+	statusCode int
+	finalURL   string
+	latency    time.Duration
+	attempts   int
+	err        error
+}
+
+// checkLinkStatus sends a HEAD request (or fallback GET), following redirects, and reports the final
+// response status code and URL. Transient failures are retried up to analyser.Retries times with
+// exponential backoff. A Retry-After that analyser.Hosts honors pauses the whole host, whether or not
+// retries are left, and replaces the backoff; a longer one ends the retries.
+func (analyser *DefaultAnalyzer) checkLinkStatus(ctx context.Context, link string) linkCheck {
+	host := hostKey(link)
+	var check linkCheck
+	for {
+		check.attempts++
+		start := time.Now()
+		resp, err := analyser.requestLink(ctx, link)
+		check = linkCheck{latency: time.Since(start), attempts: check.attempts, err: err}
+		if err == nil {
+			check.statusCode, check.finalURL = resp.StatusCode, finalURL(resp)
+		}
+
+		outcome := classifyOutcome(check.statusCode, false, err)
+		if ctx.Err() != nil || !transient(outcome, check.statusCode, err) {
+			return check
+		}
+
+		// The host is paused for the other links to it even when this one is not retried
+		delay := retryBackoff(analyser.RetryBackoff, check.attempts-1)
+		if resp != nil {
+			if wait, ok := retryAfter(resp, time.Now()); ok {
+				if !analyser.Hosts.honors(wait) {
+					return check
+				}
+				analyser.Hosts.backOff(host, wait)
+				delay = 0
+			}
+		}
+		if check.attempts > analyser.Retries {
+			return check
+		}
+		err = idle(ctx, func() error {
+			if err := sleep(ctx, delay); err != nil {
+				return err
+			}
+			return analyser.Hosts.wait(ctx, host)
+		})
+		if err != nil {
+			return check
+		}
 	}
+}
 
-	resp, err := analyser.Client.Do(req)
+// requestLink requests a link without downloading its body and returns the closed final response.
+func (analyser *DefaultAnalyzer) requestLink(ctx context.Context, link string) (*http.Response, error) {
+	// Try HEAD request to check link quickly without downloading the body
+	resp, _, err := analyser.follow(ctx, http.MethodHead, link, nil, nil)
 	if err != nil {
-		return true
+		return nil, err
 	}
-	defer func() {
-		if resp != nil && resp.Body != nil {
-			_ = resp.Body.Close()
-		}
-	}()
+	closeBody(resp)
 
 	// Fallback to GET if HEAD not allowed, since some servers do not support HEAD requests
 	if resp.StatusCode == http.StatusMethodNotAllowed {
-		req, err = http.NewRequest("GET", link, nil)
-		if err != nil {
-			return true
-		}
-		resp, err = analyser.Client.Do(req)
+		resp, _, err = analyser.follow(ctx, http.MethodGet, link, nil, nil)
 		if err != nil {
-			return true
+			return nil, err
 		}
-		defer func() {
-			if resp != nil && resp.Body != nil {
-				_ = resp.Body.Close()
-			}
-		}()
+		closeBody(resp)
 	}
-
-	return resp.StatusCode >= 400
+	return resp, nil
 }
 
 // DetectLoginForm checks if the HTML body contains a form with an input of a type "password"
 // or any attribute matches the word "login"
 func (analyser *DefaultAnalyzer) DetectLoginForm(body string) bool {
-	tokenizer := html.NewTokenizer(strings.NewReader(body))
-	for {
-		tokenType := tokenizer.Next()
-		if tokenType == html.ErrorToken {
-			break
-		}
-		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
-			continue
-		}
-		token := tokenizer.Token()
-		switch token.Data {
-		case "input":
-			// Detect login form by presence of input[type=password]
-			inputType := strings.ToLower(getAttributeValue(token, "type"))
-			if inputType == "password" {
-				return true
-			}
-		case "form":
-			// Also detect login forms by checking if form's action or class attribute contains "login"
-			action := strings.ToLower(getAttributeValue(token, "action"))
-			class := strings.ToLower(getAttributeValue(token, "class"))
-			if strings.Contains(action, "login") || strings.Contains(class, "login") {
-				return true
-			}
-		}
-	}
-	return false
+	loginForm := &loginFormCollector{}
+	walkDocument(body, loginForm)
+	return loginForm.found
 }
 
 // getAttributeValue retrieves the value of a given attribute key from an HTML token.
@@ -325,30 +625,9 @@ func getAttributeValue(tag html.Token, key string) string {
 
 // DetectHTMLVersion determines the HTML version by matching known DOCTYPE declarations in the HTML body.
 func (analyser *DefaultAnalyzer) DetectHTMLVersion(body string) string {
-	htmlDeclarations := map[string]string{
-		"HTML 5":                 "<!DOCTYPE html>",
-		"HTML 4.01 Strict":       "-//W3C//DTD HTML 4.01//EN",
-		"HTML 4.01 Transitional": "-//W3C//DTD HTML 4.01 Transitional//EN",
-		"HTML 4.01 Frameset":     "-//W3C//DTD HTML 4.01 Frameset//EN",
-		"HTML 4.0 Strict":        "-//W3C//DTD HTML 4.0//EN",
-		"HTML 3.2":               "-//W3C//DTD HTML 3.2//EN",
-		"HTML 2.0":               "-//IETF//DTD HTML 2.0//EN",
-		"HTML 1.0":               "-//IETF//DTD HTML 1.0//EN",
-		"XHTML 1.0 Strict":       "-//W3C//DTD XHTML 1.0 Strict//EN",
-		"XHTML 1.0 Transitional": "-//W3C//DTD XHTML 1.0 Transitional//EN",
-		"XHTML 1.0 Frameset":     "-//W3C//DTD XHTML 1.0 Frameset//EN",
-		"XHTML 1.1":              "-//W3C//DTD XHTML 1.1//EN",
-	}
-
-	lowerBody := strings.ToLower(body)
-
-	for version, declaration := range htmlDeclarations {
-		if strings.Contains(lowerBody, strings.ToLower(declaration)) {
-			return version
-		}
-	}
-
-	return "Unknown"
+	doctype := &doctypeCollector{}
+	walkDocument(body, doctype)
+	return doctype.result()
 }
 
 func sameHost(base, other *url.URL) bool {
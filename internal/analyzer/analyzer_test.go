@@ -1,9 +1,15 @@
 package analyzer
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -63,10 +69,11 @@ func TestFetchHTML(t *testing.T) {
 	}
 
 	analyzer := NewAnalyzer(mockClient)
-	body, err := analyzer.FetchHTML("http://example.com")
+	page, err := analyzer.FetchHTML(context.Background(), "http://example.com")
 
 	assert.NoError(t, err)
-	assert.Contains(t, body, "Welcome!")
+	assert.Contains(t, page.Body, "Welcome!")
+	assert.False(t, page.Truncated)
 }
 
 func TestExtractTitle(t *testing.T) {
@@ -78,6 +85,13 @@ func TestExtractTitle(t *testing.T) {
 	assert.Equal(t, "Welcome!", title)
 }
 
+func TestExtractTitle_IgnoresSVGTitles(t *testing.T) {
+	analyzer := NewAnalyzer(nil)
+
+	assert.Empty(t, analyzer.ExtractTitle(`<body><svg><title>Logo</title></svg></body>`))
+	assert.Equal(t, "Welcome!", analyzer.ExtractTitle(`<svg><title>Logo</title></svg><title>Welcome!</title>`))
+}
+
 func TestCountHeadings(t *testing.T) {
 	mockHTML := `
 		<html>
@@ -134,7 +148,7 @@ func TestAnalyzeLinks(t *testing.T) {
 	}
 
 	analyzer := NewAnalyzer(mockClient)
-	intCount, extCount, brokenCount, err := analyzer.AnalyzeLinks(mockHTML, "http://localhost")
+	intCount, extCount, brokenCount, err := analyzer.AnalyzeLinks(context.Background(), mockHTML, "http://localhost")
 
 	assert.NoError(t, err)
 	assert.Equal(t, 2, intCount)
@@ -174,3 +188,276 @@ func TestDetectHTMLVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyze(t *testing.T) {
+	mockHTML := `
+	<!DOCTYPE html>
+	<html>
+		<head><title>Report Page</title></head>
+		<body>
+			<h1>Main</h1>
+			<form action="/login"><input type="password"></form>
+			<a href="/about">About</a>
+			<a href="https://external.com/page">External Page</a>
+		</body>
+	</html>`
+
+	mockClient := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			body := "OK"
+			if req.URL.Path == "" {
+				body = mockHTML
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}, nil
+		},
+	}
+
+	analyzer := NewAnalyzer(mockClient)
+	report, err := analyzer.Analyze(context.Background(), "http://localhost")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost", report.URL)
+	assert.Equal(t, "HTML 5", report.HTMLVersion)
+	assert.Equal(t, "Report Page", report.Title)
+	assert.Equal(t, 1, report.Headings["h1"])
+	assert.True(t, report.HasLoginForm)
+	assert.Equal(t, LinkSummary{Internal: 1, External: 1, Broken: 0, Outcomes: map[Outcome]int{OutcomeOK: 2}}, report.Links)
+	assert.Empty(t, report.Errors)
+
+	encoded, err := json.Marshal(report)
+	assert.NoError(t, err)
+	assert.Contains(t, string(encoded), `"title":"Report Page"`)
+}
+
+func TestAnalyze_FetchFailure(t *testing.T) {
+	mockClient := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	analyzer := NewAnalyzer(mockClient)
+	report, err := analyzer.Analyze(context.Background(), "http://localhost")
+
+	assert.Error(t, err)
+	assert.Equal(t, "connection refused", report.Errors[SectionFetch])
+}
+
+func TestAnalyzeLinks_Cancelled(t *testing.T) {
+	mockHTML := `<a href="/one">One</a><a href="/two">Two</a><a href="/three">Three</a>`
+
+	var requests int32
+	mockClient := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&requests, 1)
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(strings.NewReader("OK")),
+			}, nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	analyzer := NewAnalyzer(mockClient)
+	_, _, _, err := analyzer.AnalyzeLinks(ctx, mockHTML, "http://localhost")
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+}
+
+func TestFetchHTML_ContextPropagated(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "marker")
+
+	mockClient := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "marker", req.Context().Value(ctxKey{}))
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(strings.NewReader("<html></html>")),
+			}, nil
+		},
+	}
+
+	analyzer := NewAnalyzer(mockClient)
+	_, err := analyzer.FetchHTML(ctx, "http://example.com")
+
+	assert.NoError(t, err)
+}
+
+func TestWalkDocument_SinglePassFeedsAllCollectors(t *testing.T) {
+	mockHTML := `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN">
+	<html>
+		<head><base href="http://cdn.example.com/"><title>One Pass</title></head>
+		<body>
+			<h1>Main</h1><h2>Sub</h2><h2>Sub</h2>
+			<form class="login-box"><input type="text"></form>
+			<a href="page">Page</a>
+		</body>
+	</html>`
+
+	doctype := &doctypeCollector{}
+	title := &titleCollector{}
+	headings := newHeadingCollector()
+	loginForm := &loginFormCollector{}
+	links := newLinkCollector()
+	walkDocument(mockHTML, doctype, title, headings, loginForm, links)
+
+	assert.Equal(t, "HTML 4.01 Strict", doctype.result())
+	assert.Equal(t, "One Pass", title.title)
+	assert.Equal(t, map[string]int{"h1": 1, "h2": 2}, headings.headings)
+	assert.True(t, loginForm.found)
+	assert.Equal(t, []extractedLink{{href: "page", link: "http://cdn.example.com/page", tag: "a", text: "Page"}}, links.links)
+}
+
+// largeFixturePage builds a multi-megabyte HTML page with a realistic mix of headings, links, and forms.
+func largeFixturePage(sections int) string {
+	var builder strings.Builder
+	builder.WriteString("<!DOCTYPE html><html><head><title>Large Fixture</title></head><body>")
+	for i := 0; i < sections; i++ {
+		fmt.Fprintf(&builder, "<h2>Section %d</h2><p>Lorem ipsum dolor sit amet, consectetur adipiscing elit, "+
+			"sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.</p>", i)
+		fmt.Fprintf(&builder, `<a href="/page/%d">Internal %d</a><a href="https://external.com/%d">External %d</a>`, i, i, i, i)
+		builder.WriteString(`<form action="/search"><input type="text" name="q"></form>`)
+	}
+	builder.WriteString(`<form action="/login"><input type="password"></form></body></html>`)
+	return builder.String()
+}
+
+// BenchmarkDocument_MultiPass tokenizes the page once per detector, as separate method calls do.
+func BenchmarkDocument_MultiPass(b *testing.B) {
+	body := largeFixturePage(20000)
+	analyser := &DefaultAnalyzer{}
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		analyser.DetectHTMLVersion(body)
+		analyser.ExtractTitle(body)
+		analyser.CountHeadings(body)
+		analyser.DetectLoginForm(body)
+		walkDocument(body, newLinkCollector())
+	}
+}
+
+// BenchmarkDocument_SinglePass feeds every detector from a single tokenizer pass, as Analyze does.
+func BenchmarkDocument_SinglePass(b *testing.B) {
+	body := largeFixturePage(20000)
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		walkDocument(body, &doctypeCollector{}, &titleCollector{}, newHeadingCollector(), &loginFormCollector{}, newLinkCollector())
+	}
+}
+
+func TestAnalyze_LinkDetails(t *testing.T) {
+	mockHTML := `
+	<html>
+		<head><link rel="stylesheet" href="/style.css"></head>
+		<body>
+			<a href="/missing">  Missing
+				page </a>
+			<a href="https://external.com/"><img src="logo.png" alt="Partner logo"></a>
+			<a href="http://unreachable.test/">Unreachable</a>
+		</body>
+	</html>`
+
+	mockClient := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			switch req.URL.String() {
+			case "http://localhost":
+				return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(mockHTML))}, nil
+			case "http://localhost/missing":
+				return &http.Response{StatusCode: 404, Body: io.NopCloser(strings.NewReader(""))}, nil
+			case "http://unreachable.test/":
+				return nil, &net.DNSError{Err: "no such host", Name: "unreachable.test", IsNotFound: true}
+			default:
+				return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+		},
+	}
+
+	analyzer := NewAnalyzer(mockClient)
+	report, err := analyzer.Analyze(context.Background(), "http://localhost")
+
+	assert.NoError(t, err)
+	assert.Equal(t, LinkSummary{Internal: 2, External: 2, Broken: 2, Outcomes: map[Outcome]int{OutcomeOK: 2, OutcomeClientError: 1, OutcomeDNSFailure: 1}}, report.Links)
+	assert.Len(t, report.LinkResults, 4)
+
+	stylesheet := report.LinkResults[0]
+	assert.Equal(t, "link", stylesheet.Tag)
+	assert.Equal(t, "/style.css", stylesheet.Href)
+	assert.Equal(t, "http://localhost/style.css", stylesheet.URL)
+	assert.False(t, stylesheet.Broken)
+
+	missing := report.LinkResults[1]
+	assert.Equal(t, "a", missing.Tag)
+	assert.Equal(t, "Missing page", missing.Text)
+	assert.True(t, missing.Internal)
+	assert.True(t, missing.Broken)
+	assert.Equal(t, 404, missing.StatusCode)
+	assert.Equal(t, OutcomeClientError, missing.Outcome)
+
+	partner := report.LinkResults[2]
+	assert.Equal(t, "Partner logo", partner.Text)
+	assert.False(t, partner.Internal)
+	assert.False(t, partner.Broken)
+
+	unreachable := report.LinkResults[3]
+	assert.True(t, unreachable.Broken)
+	assert.Equal(t, 0, unreachable.StatusCode)
+	assert.Equal(t, OutcomeDNSFailure, unreachable.Outcome)
+	assert.Equal(t, 1, unreachable.Attempts)
+	assert.Contains(t, unreachable.Error, "no such host")
+}
+
+func TestAnalyze_ReportsProgress(t *testing.T) {
+	mockHTML := `<a href="/one">One</a><a href="/missing">Missing</a><a href="/three">Three</a>`
+
+	mockClient := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			status := 200
+			if req.URL.Path == "/missing" {
+				status = 404
+			}
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(mockHTML))}, nil
+		},
+	}
+
+	var events []ProgressEvent
+	ctx := WithProgress(context.Background(), func(event ProgressEvent) {
+		events = append(events, event)
+	})
+
+	analyzer := NewAnalyzer(mockClient)
+	_, err := analyzer.Analyze(ctx, "http://localhost")
+
+	assert.NoError(t, err)
+	assert.Len(t, events, 6)
+	assert.Equal(t, ProgressEvent{Type: EventFetched, Total: 3}, events[0])
+
+	var checked, broken int
+	for _, event := range events[1:5] {
+		switch event.Type {
+		case EventLinkChecked:
+			checked++
+			assert.Equal(t, checked, event.Checked)
+			assert.NotNil(t, event.Link)
+		case EventBrokenLink:
+			broken++
+			assert.Equal(t, "http://localhost/missing", event.Link.URL)
+		}
+	}
+	assert.Equal(t, 3, checked)
+	assert.Equal(t, 1, broken)
+
+	done := events[5]
+	assert.Equal(t, EventDone, done.Type)
+	assert.Equal(t, &LinkSummary{Internal: 3, External: 0, Broken: 1, Outcomes: map[Outcome]int{OutcomeOK: 2, OutcomeClientError: 1}}, done.Summary)
+}
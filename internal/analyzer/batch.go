@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gayansanjeewa/gogeturl/internal/utils"
+)
+
+// BatchResult is the outcome of analyzing one URL of a batch.
+type BatchResult struct {
+	URL    string          `json:"url"`
+	Report *AnalysisReport `json:"report,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// BatchTotals aggregates the results of every URL in a batch.
+type BatchTotals struct {
+	URLs          int `json:"urls"`
+	Succeeded     int `json:"succeeded"`
+	Failed        int `json:"failed"`
+	InternalLinks int `json:"internal_links"`
+	ExternalLinks int `json:"external_links"`
+	BrokenLinks   int `json:"broken_links"`
+	LoginForms    int `json:"login_forms"`
+}
+
+// BatchReport is the combined result of a batch, with per-URL results in submission order.
+type BatchReport struct {
+	Results []BatchResult `json:"results"`
+	Totals  BatchTotals   `json:"totals"`
+}
+
+// BatchRunner analyzes many URLs at once. Its concurrency limit is shared by every batch it runs,
+// so concurrent batch requests together never exceed it.
+type BatchRunner struct {
+	analyser Analyzer
+	slots    chan struct{}
+}
+
+// NewBatchRunner creates a BatchRunner that runs at most concurrency analyses at a time across all batches.
+func NewBatchRunner(analyser Analyzer, concurrency int) *BatchRunner {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &BatchRunner{analyser: analyser, slots: make(chan struct{}, concurrency)}
+}
+
+// Run analyzes every URL and returns the combined report. Invalid URLs are reported as failures
+// without being fetched; URLs still waiting for a slot when ctx is cancelled fail with the context's error.
+func (runner *BatchRunner) Run(ctx context.Context, urls []string) *BatchReport {
+	results := make([]BatchResult, len(urls))
+
+	var waitGroup sync.WaitGroup
+	for index, targetURL := range urls {
+		results[index].URL = targetURL
+
+		if err := utils.ValidateURL(targetURL); err != nil {
+			results[index].Error = err.Error()
+			continue
+		}
+
+		waitGroup.Add(1)
+		go func(result *BatchResult) {
+			defer waitGroup.Done()
+
+			select {
+			case runner.slots <- struct{}{}:
+				defer func() { <-runner.slots }()
+			case <-ctx.Done():
+				result.Error = ctx.Err().Error()
+				return
+			}
+
+			report, err := runner.analyser.Analyze(ctx, result.URL)
+			result.Report = report
+			if err != nil {
+				result.Error = err.Error()
+			}
+		}(&results[index])
+	}
+	waitGroup.Wait()
+
+	return &BatchReport{Results: results, Totals: totalBatch(results)}
+}
+
+func totalBatch(results []BatchResult) BatchTotals {
+	totals := BatchTotals{URLs: len(results)}
+	for _, result := range results {
+		if result.Error != "" {
+			totals.Failed++
+			continue
+		}
+
+		totals.Succeeded++
+		totals.InternalLinks += result.Report.Links.Internal
+		totals.ExternalLinks += result.Report.Links.External
+		totals.BrokenLinks += result.Report.Links.Broken
+		if result.Report.HasLoginForm {
+			totals.LoginForms++
+		}
+	}
+	return totals
+}
@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingAnalyzer records the highest number of concurrent Analyze calls.
+type countingAnalyzer struct {
+	Analyzer
+	mutex   sync.Mutex
+	running int
+	peak    int
+}
+
+func (c *countingAnalyzer) Analyze(ctx context.Context, targetURL string) (*AnalysisReport, error) {
+	c.mutex.Lock()
+	c.running++
+	if c.running > c.peak {
+		c.peak = c.running
+	}
+	c.mutex.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	c.mutex.Lock()
+	c.running--
+	c.mutex.Unlock()
+
+	if targetURL == "http://down.example.com" {
+		return &AnalysisReport{URL: targetURL}, errors.New("connection refused")
+	}
+	return &AnalysisReport{
+		URL:          targetURL,
+		Links:        LinkSummary{Internal: 2, External: 1, Broken: 1},
+		HasLoginForm: targetURL == "http://example.com/login",
+	}, nil
+}
+
+func TestBatchRunner_Run(t *testing.T) {
+	counting := &countingAnalyzer{}
+	runner := NewBatchRunner(counting, 2)
+
+	urls := []string{
+		"http://example.com/",
+		"http://example.com/login",
+		"not a url",
+		"http://down.example.com",
+		"http://example.com/about",
+	}
+	report := runner.Run(context.Background(), urls)
+
+	assert.Len(t, report.Results, len(urls))
+	for index, result := range report.Results {
+		assert.Equal(t, urls[index], result.URL)
+	}
+	assert.Equal(t, "Invalid URL format", report.Results[2].Error)
+	assert.Equal(t, "connection refused", report.Results[3].Error)
+
+	assert.Equal(t, BatchTotals{
+		URLs:          5,
+		Succeeded:     3,
+		Failed:        2,
+		InternalLinks: 6,
+		ExternalLinks: 3,
+		BrokenLinks:   3,
+		LoginForms:    1,
+	}, report.Totals)
+	assert.LessOrEqual(t, counting.peak, 2)
+}
+
+func TestBatchRunner_SharesConcurrencyAcrossBatches(t *testing.T) {
+	counting := &countingAnalyzer{}
+	runner := NewBatchRunner(counting, 3)
+
+	var waitGroup sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			runner.Run(context.Background(), []string{"http://a.com", "http://b.com", "http://c.com", "http://d.com"})
+		}()
+	}
+	waitGroup.Wait()
+
+	assert.LessOrEqual(t, counting.peak, 3)
+}
+
+func TestBatchRunner_Cancelled(t *testing.T) {
+	counting := &countingAnalyzer{}
+	runner := NewBatchRunner(counting, 1)
+	runner.slots <- struct{}{} // occupy the only slot so every URL waits
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := runner.Run(ctx, []string{"http://a.com", "http://b.com"})
+
+	assert.Equal(t, 2, report.Totals.Failed)
+	assert.Equal(t, context.Canceled.Error(), report.Results[0].Error)
+}
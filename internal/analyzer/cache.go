@@ -0,0 +1,287 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL is how long NewAnalyzer reuses the result of a link check.
+	DefaultCacheTTL = 10 * time.Minute
+	// maxCacheEntries bounds the memory a long-running cache can take.
+	maxCacheEntries = 100_000
+)
+
+// CacheStats counts how the links of an analysis were answered: from the cache, by a request,
+// or by an identical link elsewhere on the same page.
+type CacheStats struct {
+	Hits       int `json:"hits"`
+	Misses     int `json:"misses"`
+	Duplicates int `json:"duplicates"`
+}
+
+// LinkCache keeps the results of link checks, keyed by resolved URL, for a fixed time.
+// Checks of the same URL that run at the same time are combined into one request.
+// Only lasting outcomes are kept; timeouts, rate limiting, server and network errors are checked again.
+// It is safe for concurrent use and is meant to be shared by every analysis. A nil *LinkCache caches nothing.
+type LinkCache struct {
+	ttl      time.Duration
+	mutex    sync.Mutex
+	entries  map[string]cacheEntry
+	inflight map[string]*inflightCheck
+	// file receives every stored entry when the cache is backed by disk
+	file *os.File
+}
+
+// cacheEntry is the result of checking one URL, in the form it is cached and written to disk.
+type cacheEntry struct {
+	URL        string        `json:"url"`
+	StatusCode int           `json:"status_code,omitempty"`
+	FinalURL   string        `json:"final_url,omitempty"`
+	Outcome    Outcome       `json:"outcome"`
+	Error      string        `json:"error,omitempty"`
+	Attempts   int           `json:"attempts"`
+	Latency    time.Duration `json:"latency_ns"`
+	CheckedAt  time.Time     `json:"checked_at"`
+}
+
+type inflightCheck struct {
+	done  chan struct{}
+	entry cacheEntry
+	// abandoned is set when the context of the caller that ran the check ended first, so its
+	// entry says nothing about the URL and waiters check it again
+	abandoned bool
+}
+
+// NewLinkCache creates an in-memory LinkCache that keeps results for ttl.
+func NewLinkCache(ttl time.Duration) *LinkCache {
+	return &LinkCache{ttl: ttl, entries: make(map[string]cacheEntry), inflight: make(map[string]*inflightCheck)}
+}
+
+// OpenLinkCache creates a LinkCache backed by the file at path, so results outlive the process.
+// Unexpired results are loaded and the file is rewritten without the expired ones; every new
+// result is then appended to it. Close releases the file.
+func OpenLinkCache(path string, ttl time.Duration) (*LinkCache, error) {
+	cache := NewLinkCache(ttl)
+	if err := cache.load(path); err != nil {
+		return nil, err
+	}
+
+	// Compact through a temporary file so a crash never leaves a half-written cache behind.
+	// The file keeps the mode it had, since the temporary one is only readable by its owner.
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), ".linkcache-*")
+	if err != nil {
+		return nil, err
+	}
+	if err := temp.Chmod(mode); err != nil {
+		_ = temp.Close()
+		_ = os.Remove(temp.Name())
+		return nil, err
+	}
+	encoder := json.NewEncoder(temp)
+	for _, entry := range cache.entries {
+		if err := encoder.Encode(entry); err != nil {
+			_ = temp.Close()
+			_ = os.Remove(temp.Name())
+			return nil, err
+		}
+	}
+	if err := temp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		_ = os.Remove(temp.Name())
+		return nil, err
+	}
+
+	cache.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// Close releases the file of a disk-backed cache. The cache keeps working in memory.
+func (cache *LinkCache) Close() error {
+	if cache == nil {
+		return nil
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.file == nil {
+		return nil
+	}
+	err := cache.file.Close()
+	cache.file = nil
+	return err
+}
+
+// load reads the unexpired entries of a cache file, the latest entry of each URL winning.
+// A missing file is an empty cache, and lines that cannot be parsed are skipped.
+func (cache *LinkCache) load(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	now := time.Now()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry cacheEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil || entry.URL == "" {
+			continue
+		}
+		if cache.fresh(entry, now) {
+			cache.entries[entry.URL] = entry
+		}
+	}
+	return scanner.Err()
+}
+
+// get returns the cached result for url if there is an unexpired one.
+func (cache *LinkCache) get(url string) (cacheEntry, bool) {
+	if cache == nil {
+		return cacheEntry{}, false
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	entry, ok := cache.entries[url]
+	if ok && !cache.fresh(entry, time.Now()) {
+		delete(cache.entries, url)
+		return cacheEntry{}, false
+	}
+	return entry, ok
+}
+
+// check returns the cached result for url, waits for a check of url already in progress, or calls
+// fetch and caches what it returns. cached is set when fetch was not called. A check cut short by the
+// end of its caller's ctx is never cached; the callers waiting on it check url again. err is ctx.Err()
+// once ctx is done, and entry is then empty.
+func (cache *LinkCache) check(ctx context.Context, url string, fetch func() cacheEntry) (entry cacheEntry, cached bool, err error) {
+	if cache == nil {
+		entry = fetch()
+		if err := ctx.Err(); err != nil {
+			return cacheEntry{}, false, err
+		}
+		return entry, false, nil
+	}
+	if entry, ok := cache.get(url); ok {
+		return entry, true, nil
+	}
+
+	cache.mutex.Lock()
+	for {
+		call, ok := cache.inflight[url]
+		if !ok {
+			break
+		}
+		cache.mutex.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return cacheEntry{}, false, ctx.Err()
+		}
+		if !call.abandoned {
+			return call.entry, true, nil
+		}
+		cache.mutex.Lock()
+	}
+	call := &inflightCheck{done: make(chan struct{})}
+	cache.inflight[url] = call
+	cache.mutex.Unlock()
+
+	entry = fetch()
+	err = ctx.Err()
+
+	cache.mutex.Lock()
+	delete(cache.inflight, url)
+	if err == nil && entry.Outcome.cacheable() {
+		cache.store(entry)
+	}
+	cache.mutex.Unlock()
+
+	call.entry, call.abandoned = entry, err != nil
+	close(call.done)
+	if err != nil {
+		return cacheEntry{}, false, err
+	}
+	return entry, false, nil
+}
+
+// store keeps entry, making room first if the cache is full. The caller must hold the mutex.
+func (cache *LinkCache) store(entry cacheEntry) {
+	if _, exists := cache.entries[entry.URL]; !exists && len(cache.entries) >= maxCacheEntries {
+		now := time.Now()
+		for url, existing := range cache.entries {
+			if !cache.fresh(existing, now) {
+				delete(cache.entries, url)
+			}
+		}
+		// Still full of fresh entries: drop an arbitrary one
+		for url := range cache.entries {
+			if len(cache.entries) < maxCacheEntries {
+				break
+			}
+			delete(cache.entries, url)
+		}
+	}
+	cache.entries[entry.URL] = entry
+
+	if cache.file != nil {
+		if err := json.NewEncoder(cache.file).Encode(entry); err != nil {
+			slog.Warn("Failed to write link cache", "error", err)
+		}
+	}
+}
+
+func (cache *LinkCache) fresh(entry cacheEntry, now time.Time) bool {
+	return now.Sub(entry.CheckedAt) < cache.ttl
+}
+
+// cacheable reports whether a link with this outcome is likely to give the same result for a while.
+func (outcome Outcome) cacheable() bool {
+	switch outcome {
+	case OutcomeOK, OutcomeRedirect, OutcomeClientError, OutcomeDNSFailure, OutcomeTLSError:
+		return true
+	}
+	return false
+}
+
+// summarizeCache counts, for the links that were checked, how many came from the cache, how many
+// were requested, and how many repeated a URL already seen on the page. Links robots.txt kept
+// from being requested are not counted.
+func summarizeCache(results []LinkResult) CacheStats {
+	var stats CacheStats
+	seen := map[string]bool{}
+	for _, result := range results {
+		if result.Outcome == "" || result.Outcome == OutcomeInvalidURL || result.Outcome == OutcomeBlockedByRobots {
+			continue
+		}
+		switch {
+		case seen[result.URL]:
+			stats.Duplicates++
+		case result.Cached:
+			stats.Hits++
+		default:
+			stats.Misses++
+		}
+		seen[result.URL] = true
+	}
+	return stats
+}
@@ -0,0 +1,271 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze_ReusesCachedLinks(t *testing.T) {
+	pages := map[string]string{
+		"/one": `<a href="/shared">Home</a><a href="/missing">Gone</a><a href="/one-only">One</a>`,
+		"/two": `<a href="/shared">Home</a><a href="/missing">Gone</a><a href="/flaky">Flaky</a>`,
+	}
+	requested := map[string]int{}
+	var mutex sync.Mutex
+	client := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			mutex.Lock()
+			requested[req.URL.Path]++
+			mutex.Unlock()
+
+			status, body := 200, "OK"
+			switch req.URL.Path {
+			case "/missing":
+				status = 404
+			case "/flaky":
+				status = 503
+			}
+			if page, ok := pages[req.URL.Path]; ok {
+				body = page
+			}
+			return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}
+
+	analyser := NewAnalyzer(client).(*DefaultAnalyzer)
+	analyser.Retries = 0
+
+	first, err := analyser.Analyze(context.Background(), "http://localhost/one")
+	require.NoError(t, err)
+	assert.Equal(t, CacheStats{Misses: 3}, first.LinkCache)
+
+	second, err := analyser.Analyze(context.Background(), "http://localhost/two")
+	require.NoError(t, err)
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1}, second.LinkCache)
+
+	// Working and missing links are reused; the server error is checked again next time
+	assert.Equal(t, 1, requested["/shared"])
+	assert.Equal(t, 1, requested["/missing"])
+	assert.True(t, second.LinkResults[0].Cached)
+	assert.True(t, second.LinkResults[1].Cached)
+	assert.Equal(t, 404, second.LinkResults[1].StatusCode)
+	assert.True(t, second.LinkResults[1].Broken)
+	assert.False(t, second.LinkResults[2].Cached)
+
+	_, err = analyser.Analyze(context.Background(), "http://localhost/two")
+	require.NoError(t, err)
+	assert.Equal(t, 2, requested["/flaky"])
+}
+
+func TestCheckLinks_DeduplicatesWithinPage(t *testing.T) {
+	var requests atomic.Int32
+	client := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			requests.Add(1)
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("OK"))}, nil
+		},
+	}
+	links := []extractedLink{
+		{href: "/about", link: "/about", tag: "a", text: "About"},
+		{href: "about", link: "about", tag: "a", text: "About us"},
+		{href: "http://localhost/about", link: "http://localhost/about", tag: "link"},
+	}
+
+	analyser := NewAnalyzer(client).(*DefaultAnalyzer)
+	analyser.Cache = nil
+	results, err := analyser.checkLinks(context.Background(), links, "http://localhost/")
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Equal(t, CacheStats{Misses: 1, Duplicates: 2}, summarizeCache(results))
+	for index, result := range results {
+		assert.Equal(t, "http://localhost/about", result.URL)
+		assert.Equal(t, OutcomeOK, result.Outcome)
+		assert.Equal(t, links[index].href, result.Href)
+		assert.Equal(t, links[index].text, result.Text)
+		assert.Equal(t, links[index].tag, result.Tag)
+	}
+}
+
+func TestLinkCache_CombinesConcurrentChecks(t *testing.T) {
+	cache := NewLinkCache(time.Minute)
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	fetch := func() cacheEntry {
+		fetches.Add(1)
+		<-release
+		return cacheEntry{URL: "https://example.com", StatusCode: 200, Outcome: OutcomeOK, CheckedAt: time.Now()}
+	}
+
+	var waitGroup sync.WaitGroup
+	cached := make([]bool, 5)
+	for index := range cached {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			_, cached[index], _ = cache.check(context.Background(), "https://example.com", fetch)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	waitGroup.Wait()
+
+	assert.Equal(t, int32(1), fetches.Load())
+	hits := 0
+	for _, hit := range cached {
+		if hit {
+			hits++
+		}
+	}
+	assert.Equal(t, 4, hits)
+}
+
+func TestAnalyze_SharedCheckSurvivesCancelledAnalysis(t *testing.T) {
+	started := make(chan struct{})
+	var sharedRequests atomic.Int32
+	client := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			switch req.URL.Path {
+			case "/a", "/b":
+				return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`<a href="/shared">Home</a>`))}, nil
+			case "/shared":
+				// The first check hangs until the analysis that started it is cancelled
+				if sharedRequests.Add(1) == 1 {
+					close(started)
+					<-req.Context().Done()
+					return nil, req.Context().Err()
+				}
+			}
+			return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("OK"))}, nil
+		},
+	}
+	analyser := NewAnalyzer(client).(*DefaultAnalyzer)
+	analyser.Robots = nil
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := analyser.Analyze(ctx, "http://localhost/a")
+		cancelled <- err
+	}()
+	<-started
+
+	second := make(chan *AnalysisReport, 1)
+	go func() {
+		report, err := analyser.Analyze(context.Background(), "http://localhost/b")
+		assert.NoError(t, err)
+		second <- report
+	}()
+	// Let the second analysis wait on the check already in flight before the first one is cancelled
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	require.NoError(t, <-cancelled)
+
+	report := <-second
+	assert.Equal(t, 0, report.Links.Broken)
+	require.Len(t, report.LinkResults, 1)
+	assert.Equal(t, OutcomeOK, report.LinkResults[0].Outcome)
+	assert.Equal(t, int32(2), sharedRequests.Load())
+
+	entry, ok := analyser.Cache.get("http://localhost/shared")
+	assert.True(t, ok)
+	assert.Equal(t, OutcomeOK, entry.Outcome)
+}
+
+func TestLinkCache_CancelledWaiter(t *testing.T) {
+	cache := NewLinkCache(time.Minute)
+	release := make(chan struct{})
+	go cache.check(context.Background(), "https://example.com", func() cacheEntry {
+		<-release
+		return cacheEntry{URL: "https://example.com", Outcome: OutcomeOK, CheckedAt: time.Now()}
+	})
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	entry, cached, err := cache.check(ctx, "https://example.com", func() cacheEntry {
+		t.Error("a waiter should not check the URL itself")
+		return cacheEntry{}
+	})
+	close(release)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, cached)
+	assert.Empty(t, entry.Outcome)
+}
+
+func TestLinkCache_Expires(t *testing.T) {
+	cache := NewLinkCache(time.Minute)
+	cache.entries["https://example.com"] = cacheEntry{URL: "https://example.com", Outcome: OutcomeOK, CheckedAt: time.Now().Add(-2 * time.Minute)}
+
+	_, ok := cache.get("https://example.com")
+
+	assert.False(t, ok)
+	assert.Empty(t, cache.entries)
+}
+
+func TestOpenLinkCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.jsonl")
+
+	// An expired entry and an unparsable line are dropped when the file is compacted
+	stale, err := json.Marshal(cacheEntry{URL: "https://stale.example", Outcome: OutcomeOK, CheckedAt: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, append(stale, []byte("\nnot json\n")...), 0o644))
+
+	cache, err := OpenLinkCache(path, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, cache.entries)
+
+	cache.check(context.Background(), "https://example.com", func() cacheEntry {
+		return cacheEntry{URL: "https://example.com", StatusCode: 404, Outcome: OutcomeClientError, CheckedAt: time.Now()}
+	})
+	cache.check(context.Background(), "https://slow.example", func() cacheEntry {
+		return cacheEntry{URL: "https://slow.example", Outcome: OutcomeTimeout, CheckedAt: time.Now()}
+	})
+	require.NoError(t, cache.Close())
+
+	reopened, err := OpenLinkCache(path, time.Minute)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	entry, ok := reopened.get("https://example.com")
+	assert.True(t, ok)
+	assert.Equal(t, 404, entry.StatusCode)
+	_, ok = reopened.get("https://slow.example")
+	assert.False(t, ok)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(contents), "\n"))
+}
+
+func TestOpenLinkCache_KeepsFileMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.jsonl")
+
+	cache, err := OpenLinkCache(path, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, cache.Close())
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	require.NoError(t, os.Chmod(path, 0o640))
+	cache, err = OpenLinkCache(path, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, cache.Close())
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+}
@@ -0,0 +1,307 @@
+package analyzer
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+)
+
+const (
+	// DefaultCrawlDepth is how many links away from the start page a crawl goes unless told otherwise.
+	DefaultCrawlDepth = 2
+	// DefaultCrawlPages is the number of pages a crawl analyzes unless told otherwise.
+	DefaultCrawlPages = 50
+)
+
+// Kinds of SiteIssue.
+const (
+	IssueMissingTitle   = "missing_title"
+	IssueDuplicateTitle = "duplicate_title"
+	IssueMissingH1      = "missing_h1"
+	IssueMultipleH1     = "multiple_h1"
+)
+
+// CrawlOptions limits how far a crawl goes.
+type CrawlOptions struct {
+	// MaxDepth is how many links away from the start page pages are analyzed; zero analyzes only the start page.
+	MaxDepth int
+	// MaxPages caps the number of pages analyzed.
+	MaxPages int
+}
+
+// SiteReport is the result of crawling a site: every page analyzed and what was found across them.
+type SiteReport struct {
+	StartURL string        `json:"start_url"`
+	Pages    []CrawledPage `json:"pages"`
+	// NonHTML lists linked URLs that turned out not to be HTML pages, so were not analyzed.
+	NonHTML []string `json:"non_html,omitempty"`
+	// Disallowed lists links of the site that were not followed because robots.txt disallows them.
+	Disallowed  []string     `json:"disallowed,omitempty"`
+	BrokenLinks []BrokenLink `json:"broken_links"`
+	LoginPages  []string     `json:"login_pages"`
+	Issues      []SiteIssue  `json:"issues"`
+	Totals      SiteTotals   `json:"totals"`
+	// Truncated is set when MaxPages stopped the crawl before every page within MaxDepth was analyzed.
+	Truncated bool `json:"truncated"`
+}
+
+// CrawledPage is one page of a crawl. Referrer is the page it was first found on, empty for the start page.
+type CrawledPage struct {
+	URL      string          `json:"url"`
+	Depth    int             `json:"depth"`
+	Referrer string          `json:"referrer,omitempty"`
+	Report   *AnalysisReport `json:"report,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// BrokenLink is a broken link or subresource with every crawled page that refers to it.
+type BrokenLink struct {
+	URL        string   `json:"url"`
+	StatusCode int      `json:"status_code,omitempty"`
+	Outcome    Outcome  `json:"outcome"`
+	Error      string   `json:"error,omitempty"`
+	Referrers  []string `json:"referrers"`
+}
+
+// SiteIssue is a title or heading problem, with the pages it affects. Detail holds the duplicated title.
+type SiteIssue struct {
+	Kind   string   `json:"kind"`
+	Detail string   `json:"detail,omitempty"`
+	Pages  []string `json:"pages"`
+}
+
+// SiteTotals aggregates a crawl.
+type SiteTotals struct {
+	Pages       int `json:"pages"`
+	Failed      int `json:"failed"`
+	BrokenLinks int `json:"broken_links"`
+	LoginPages  int `json:"login_pages"`
+	Issues      int `json:"issues"`
+}
+
+// Crawler analyzes a site by following its internal links. Its concurrency limit is shared by every
+// crawl it runs, so concurrent crawls together never analyze more pages at once than that.
+type Crawler struct {
+	analyser Analyzer
+	slots    chan struct{}
+}
+
+// NewCrawler creates a Crawler that analyzes at most concurrency pages at a time across all crawls.
+func NewCrawler(analyser Analyzer, concurrency int) *Crawler {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Crawler{analyser: analyser, slots: make(chan struct{}, concurrency)}
+}
+
+// Crawl analyzes startURL and then, level by level, the pages its links lead to on the same host,
+// up to options.MaxDepth links away and options.MaxPages pages in total. The host is the one the start
+// page was finally served from. Links that the analyzer found disallowed by robots.txt are not followed.
+// Pages that fail are reported with their error; if ctx is cancelled,
+// pages not yet analyzed fail with the context's error.
+func (crawler *Crawler) Crawl(ctx context.Context, startURL string, options CrawlOptions) *SiteReport {
+	report := &SiteReport{StartURL: startURL}
+	if options.MaxPages < 1 {
+		options.MaxPages = 1
+	}
+
+	visited := map[string]bool{crawlKey(startURL): true}
+	frontier := []CrawledPage{{URL: startURL}}
+	siteHost := ""
+
+	for len(frontier) > 0 && len(report.Pages) < options.MaxPages {
+		if room := options.MaxPages - len(report.Pages); len(frontier) > room {
+			frontier = frontier[:room]
+			report.Truncated = true
+		}
+		errs := crawler.analyzeLevel(ctx, frontier)
+
+		// A page reached through a redirect is not analyzed again when linked by its final URL
+		for _, page := range frontier {
+			if page.Report != nil && page.Report.FinalURL != "" {
+				visited[crawlKey(page.Report.FinalURL)] = true
+			}
+		}
+
+		var next []CrawledPage
+		for index, page := range frontier {
+			var contentTypeErr *ContentTypeError
+			if errors.As(errs[index], &contentTypeErr) {
+				report.NonHTML = append(report.NonHTML, page.URL)
+				continue
+			}
+			report.Pages = append(report.Pages, page)
+			if page.Report == nil || page.Error != "" {
+				continue
+			}
+
+			if siteHost == "" {
+				siteHost = hostOf(cmp.Or(page.Report.FinalURL, page.URL))
+			}
+			if page.Depth >= options.MaxDepth {
+				continue
+			}
+			for _, link := range page.Report.LinkResults {
+				target, ok := followable(link, siteHost)
+				if !ok || visited[crawlKey(target)] {
+					continue
+				}
+				visited[crawlKey(target)] = true
+				if link.Outcome == OutcomeBlockedByRobots {
+					report.Disallowed = append(report.Disallowed, target)
+					continue
+				}
+				next = append(next, CrawledPage{URL: target, Depth: page.Depth + 1, Referrer: page.URL})
+			}
+		}
+		frontier = next
+	}
+	if len(frontier) > 0 {
+		report.Truncated = true
+	}
+
+	summarizeSite(report)
+	return report
+}
+
+// analyzeLevel analyzes the pages of one depth concurrently, filling in their reports,
+// and returns the error each analysis failed with.
+func (crawler *Crawler) analyzeLevel(ctx context.Context, pages []CrawledPage) []error {
+	errs := make([]error, len(pages))
+	var waitGroup sync.WaitGroup
+	for index := range pages {
+		waitGroup.Add(1)
+		go func(page *CrawledPage, err *error) {
+			defer waitGroup.Done()
+
+			// Pages still waiting for a slot once the crawl is abandoned are not analyzed
+			if *err = ctx.Err(); *err == nil {
+				select {
+				case crawler.slots <- struct{}{}:
+					defer func() { <-crawler.slots }()
+				case <-ctx.Done():
+					*err = ctx.Err()
+				}
+			}
+			if *err != nil {
+				page.Error = (*err).Error()
+				return
+			}
+
+			page.Report, *err = crawler.analyser.Analyze(ctx, page.URL)
+			if *err != nil {
+				page.Error = (*err).Error()
+			}
+		}(&pages[index], &errs[index])
+	}
+	waitGroup.Wait()
+	return errs
+}
+
+// followable returns the URL a link leads to if it is a working link to another page of the site.
+func followable(link LinkResult, siteHost string) (string, bool) {
+	if link.Tag != "a" || link.Broken {
+		return "", false
+	}
+	target := link.URL
+	if link.RedirectedTo != "" {
+		target = link.RedirectedTo
+	}
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() != siteHost {
+		return "", false
+	}
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	return parsed.String(), true
+}
+
+// crawlKey identifies a page regardless of the fragment of the link that led to it.
+func crawlKey(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	return parsed.String()
+}
+
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// summarizeSite collects broken links, login pages and title and heading issues across the crawled pages.
+func summarizeSite(report *SiteReport) {
+	broken := map[string]int{}
+	titles := map[string][]string{}
+	var titleOrder []string
+	missingTitle := SiteIssue{Kind: IssueMissingTitle}
+	missingH1 := SiteIssue{Kind: IssueMissingH1}
+	multipleH1 := SiteIssue{Kind: IssueMultipleH1}
+
+	for _, page := range report.Pages {
+		if page.Error != "" || page.Report == nil {
+			report.Totals.Failed++
+			continue
+		}
+		pageReport := page.Report
+
+		for _, link := range append(pageReport.LinkResults[:len(pageReport.LinkResults):len(pageReport.LinkResults)], pageReport.Resources.Results...) {
+			if !link.Broken {
+				continue
+			}
+			index, seen := broken[link.URL]
+			if !seen {
+				index = len(report.BrokenLinks)
+				broken[link.URL] = index
+				report.BrokenLinks = append(report.BrokenLinks, BrokenLink{URL: link.URL, StatusCode: link.StatusCode, Outcome: link.Outcome, Error: link.Error})
+			}
+			referrers := report.BrokenLinks[index].Referrers
+			if len(referrers) == 0 || referrers[len(referrers)-1] != page.URL {
+				report.BrokenLinks[index].Referrers = append(referrers, page.URL)
+			}
+		}
+
+		if pageReport.HasLoginForm {
+			report.LoginPages = append(report.LoginPages, page.URL)
+		}
+
+		if pageReport.Title == "" {
+			missingTitle.Pages = append(missingTitle.Pages, page.URL)
+		} else {
+			if _, seen := titles[pageReport.Title]; !seen {
+				titleOrder = append(titleOrder, pageReport.Title)
+			}
+			titles[pageReport.Title] = append(titles[pageReport.Title], page.URL)
+		}
+		switch h1 := pageReport.Headings["h1"]; {
+		case h1 == 0:
+			missingH1.Pages = append(missingH1.Pages, page.URL)
+		case h1 > 1:
+			multipleH1.Pages = append(multipleH1.Pages, page.URL)
+		}
+	}
+
+	for _, issue := range []SiteIssue{missingTitle, missingH1, multipleH1} {
+		if len(issue.Pages) > 0 {
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+	for _, title := range titleOrder {
+		if pages := titles[title]; len(pages) > 1 {
+			report.Issues = append(report.Issues, SiteIssue{Kind: IssueDuplicateTitle, Detail: title, Pages: pages})
+		}
+	}
+
+	report.Totals.Pages = len(report.Pages)
+	report.Totals.BrokenLinks = len(report.BrokenLinks)
+	report.Totals.LoginPages = len(report.LoginPages)
+	report.Totals.Issues = len(report.Issues)
+}
@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crawlSite serves a small site on site.test; the paths not listed are plain 200 responses.
+var crawlSite = map[string]string{
+	"/": `<title>Home</title><h1>Welcome</h1>
+		<a href="/about">About</a><a href="/about#team">Team section</a><a href="/blog">Blog</a>
+		<a href="https://other.test/">Elsewhere</a><a href="/missing">Missing</a><a href="/report.pdf">Report</a>`,
+	"/about": `<title>About</title><a href="/">Home</a><a href="/team">Team</a><a href="/missing">Missing</a>`,
+	"/blog": `<title>Home</title><h1>Blog</h1><h1>Posts</h1><a href="/blog/first">First</a>
+		<form><input type="password"></form>`,
+	"/team":       `<a href="/deep">Deeper</a>`,
+	"/blog/first": `<title>First</title><h1>First post</h1><img src="/img/gone.png">`,
+	"/deep":       `<title>Deep</title><h1>Too deep</h1>`,
+}
+
+func newCrawlSiteClient() *mockHTTPClient {
+	return &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"Content-Type": {"text/html"}}
+			status, body := 200, ""
+			switch {
+			case req.URL.Host != "site.test":
+			case req.URL.Path == "/missing", req.URL.Path == "/img/gone.png":
+				status = 404
+			case req.URL.Path == "/report.pdf":
+				header.Set("Content-Type", "application/pdf")
+			default:
+				body = crawlSite[req.URL.Path]
+			}
+			return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}
+}
+
+func TestCrawler_Crawl(t *testing.T) {
+	crawler := NewCrawler(NewAnalyzer(newCrawlSiteClient()), 2)
+
+	report := crawler.Crawl(context.Background(), "http://site.test/", CrawlOptions{MaxDepth: 2, MaxPages: 20})
+
+	var pages []string
+	for _, page := range report.Pages {
+		assert.Empty(t, page.Error, page.URL)
+		pages = append(pages, page.URL)
+	}
+	assert.Equal(t, []string{
+		"http://site.test/",
+		"http://site.test/about",
+		"http://site.test/blog",
+		"http://site.test/team",
+		"http://site.test/blog/first",
+	}, pages)
+	assert.Equal(t, 1, report.Pages[1].Depth)
+	assert.Equal(t, "http://site.test/", report.Pages[1].Referrer)
+	assert.Equal(t, "http://site.test/about", report.Pages[3].Referrer)
+	assert.Equal(t, []string{"http://site.test/report.pdf"}, report.NonHTML)
+	assert.False(t, report.Truncated)
+
+	assert.Equal(t, []BrokenLink{
+		{URL: "http://site.test/missing", StatusCode: 404, Outcome: OutcomeClientError, Referrers: []string{"http://site.test/", "http://site.test/about"}},
+		{URL: "http://site.test/img/gone.png", StatusCode: 404, Outcome: OutcomeClientError, Referrers: []string{"http://site.test/blog/first"}},
+	}, report.BrokenLinks)
+	assert.Equal(t, []string{"http://site.test/blog"}, report.LoginPages)
+
+	assert.Equal(t, []SiteIssue{
+		{Kind: IssueMissingTitle, Pages: []string{"http://site.test/team"}},
+		{Kind: IssueMissingH1, Pages: []string{"http://site.test/about", "http://site.test/team"}},
+		{Kind: IssueMultipleH1, Pages: []string{"http://site.test/blog"}},
+		{Kind: IssueDuplicateTitle, Detail: "Home", Pages: []string{"http://site.test/", "http://site.test/blog"}},
+	}, report.Issues)
+	assert.Equal(t, SiteTotals{Pages: 5, BrokenLinks: 2, LoginPages: 1, Issues: 4}, report.Totals)
+}
+
+func TestCrawler_Limits(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   CrawlOptions
+		pages     int
+		truncated bool
+	}{
+		{"start page only", CrawlOptions{MaxDepth: 0, MaxPages: 20}, 1, false},
+		{"page limit", CrawlOptions{MaxDepth: 5, MaxPages: 2}, 2, true},
+		{"whole site", CrawlOptions{MaxDepth: 5, MaxPages: 20}, 6, false},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			crawler := NewCrawler(NewAnalyzer(newCrawlSiteClient()), 2)
+
+			report := crawler.Crawl(context.Background(), "http://site.test/", testCase.options)
+
+			assert.Len(t, report.Pages, testCase.pages)
+			assert.Equal(t, testCase.truncated, report.Truncated)
+		})
+	}
+}
+
+func TestCrawler_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := NewCrawler(NewAnalyzer(newCrawlSiteClient()), 2).Crawl(ctx, "http://site.test/", CrawlOptions{MaxDepth: 2, MaxPages: 20})
+
+	require.Len(t, report.Pages, 1)
+	assert.NotEmpty(t, report.Pages[0].Error)
+	assert.Equal(t, 1, report.Totals.Failed)
+}
@@ -0,0 +1,490 @@
+package analyzer
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// collector is fed every token of a document during a single tokenizer pass.
+// Each detector implements it so that all of them can share one walk over the body.
+type collector interface {
+	collect(tokenType html.TokenType, token html.Token)
+}
+
+// walkDocument tokenizes the HTML body exactly once and hands each token to every collector in order.
+func walkDocument(body string, collectors ...collector) {
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return // stop at the end of the document
+		}
+
+		token := tokenizer.Token()
+		for _, c := range collectors {
+			c.collect(tokenType, token)
+		}
+	}
+}
+
+// htmlDeclarations maps DOCTYPE public identifiers to HTML versions.
+// It is a slice rather than a map so that matching is deterministic.
+var htmlDeclarations = []struct {
+	version     string
+	declaration string
+}{
+	{"HTML 4.01 Strict", "-//W3C//DTD HTML 4.01//EN"},
+	{"HTML 4.01 Transitional", "-//W3C//DTD HTML 4.01 Transitional//EN"},
+	{"HTML 4.01 Frameset", "-//W3C//DTD HTML 4.01 Frameset//EN"},
+	{"HTML 4.0 Strict", "-//W3C//DTD HTML 4.0//EN"},
+	{"HTML 3.2", "-//W3C//DTD HTML 3.2//EN"},
+	{"HTML 2.0", "-//IETF//DTD HTML 2.0//EN"},
+	{"HTML 1.0", "-//IETF//DTD HTML 1.0//EN"},
+	{"XHTML 1.0 Strict", "-//W3C//DTD XHTML 1.0 Strict//EN"},
+	{"XHTML 1.0 Transitional", "-//W3C//DTD XHTML 1.0 Transitional//EN"},
+	{"XHTML 1.0 Frameset", "-//W3C//DTD XHTML 1.0 Frameset//EN"},
+	{"XHTML 1.1", "-//W3C//DTD XHTML 1.1//EN"},
+}
+
+// doctypeCollector detects the HTML version from the first DOCTYPE declaration.
+type doctypeCollector struct {
+	version string
+}
+
+func (c *doctypeCollector) collect(tokenType html.TokenType, token html.Token) {
+	if tokenType != html.DoctypeToken || c.version != "" {
+		return
+	}
+
+	doctype := strings.ToLower(strings.TrimSpace(token.Data))
+	if doctype == "html" {
+		c.version = "HTML 5"
+		return
+	}
+
+	for _, known := range htmlDeclarations {
+		if strings.Contains(doctype, strings.ToLower(known.declaration)) {
+			c.version = known.version
+			return
+		}
+	}
+}
+
+func (c *doctypeCollector) result() string {
+	if c.version == "" {
+		return "Unknown"
+	}
+	return c.version
+}
+
+// titleCollector captures the text of the first <title> element of the document, ignoring
+// the titles of inline <svg> graphics.
+type titleCollector struct {
+	title   string
+	inTitle bool
+	found   bool
+	// svgDepth counts the open <svg> elements, as in seoCollector
+	svgDepth int
+}
+
+func (c *titleCollector) collect(tokenType html.TokenType, token html.Token) {
+	if c.found {
+		return
+	}
+
+	if c.inTitle {
+		c.inTitle = false
+		if tokenType == html.TextToken {
+			c.title = token.Data
+			c.found = true
+			return
+		}
+	}
+
+	switch {
+	case tokenType == html.StartTagToken && token.Data == "svg":
+		c.svgDepth++
+	case tokenType == html.EndTagToken && token.Data == "svg" && c.svgDepth > 0:
+		c.svgDepth--
+	case tokenType == html.StartTagToken && token.Data == "title" && c.svgDepth == 0:
+		c.inTitle = true
+	}
+}
+
+// headingCollector counts h1-h6 start tags by level.
+type headingCollector struct {
+	headings map[string]int
+}
+
+func newHeadingCollector() *headingCollector {
+	return &headingCollector{headings: make(map[string]int)}
+}
+
+func (c *headingCollector) collect(tokenType html.TokenType, token html.Token) {
+	if tokenType != html.StartTagToken {
+		return
+	}
+
+	switch token.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		c.headings[token.Data]++
+	}
+}
+
+// loginFormCollector detects a form with an input of type "password"
+// or a form whose action or class mentions "login".
+type loginFormCollector struct {
+	found bool
+}
+
+func (c *loginFormCollector) collect(tokenType html.TokenType, token html.Token) {
+	if c.found || (tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken) {
+		return
+	}
+
+	switch token.Data {
+	case "input":
+		// Detect login form by presence of input[type=password]
+		inputType := strings.ToLower(getAttributeValue(token, "type"))
+		if inputType == "password" {
+			c.found = true
+		}
+	case "form":
+		// Also detect login forms by checking if form's action or class attribute contains "login"
+		action := strings.ToLower(getAttributeValue(token, "action"))
+		class := strings.ToLower(getAttributeValue(token, "class"))
+		if strings.Contains(action, "login") || strings.Contains(class, "login") {
+			c.found = true
+		}
+	}
+}
+
+// extractedLink is a link found in the document before it has been checked.
+type extractedLink struct {
+	href string // the attribute value as written in the document
+	link string // href resolved against a <base> tag, if any
+	tag  string
+	text string
+	kind ResourceType // set for subresources from the resource inventory
+}
+
+// linkCollector gathers the href of every <a> and <link> tag, honoring a preceding <base> tag,
+// along with the visible text of each anchor.
+type linkCollector struct {
+	baseParsed *url.URL
+	links      []extractedLink
+	// openAnchor is the index of the <a> whose text is currently being collected, or -1
+	openAnchor int
+	anchorText strings.Builder
+}
+
+func newLinkCollector() *linkCollector {
+	return &linkCollector{openAnchor: -1}
+}
+
+func (c *linkCollector) collect(tokenType html.TokenType, token html.Token) {
+	switch tokenType {
+	case html.TextToken:
+		if c.openAnchor >= 0 {
+			c.anchorText.WriteString(token.Data)
+		}
+		return
+	case html.EndTagToken:
+		if token.Data == "a" {
+			c.closeAnchor()
+		}
+		return
+	case html.StartTagToken, html.SelfClosingTagToken:
+	default:
+		return
+	}
+
+	switch token.Data {
+	case "base":
+		// Parse the <base> tag to resolve relative URLs correctly
+		c.baseParsed = extractBaseHref(token)
+	case "img":
+		// Image-only anchors are described by their alt text
+		if c.openAnchor >= 0 {
+			c.anchorText.WriteString(" " + getAttributeValue(token, "alt"))
+		}
+	case "a":
+		// Anchors cannot nest, so a new <a> implicitly closes the previous one
+		c.closeAnchor()
+		before := len(c.links)
+		c.links = extractLinks(token, c.baseParsed, c.links)
+		if len(c.links) > before && tokenType == html.StartTagToken {
+			c.openAnchor = len(c.links) - 1
+		}
+	case "link":
+		c.links = extractLinks(token, c.baseParsed, c.links)
+	}
+}
+
+// closeAnchor stores the collected, whitespace-normalized text on the open anchor.
+func (c *linkCollector) closeAnchor() {
+	if c.openAnchor >= 0 {
+		c.links[c.openAnchor].text = strings.Join(strings.Fields(c.anchorText.String()), " ")
+	}
+	c.openAnchor = -1
+	c.anchorText.Reset()
+}
+
+// extractedResource is a subresource the document loads, or a form it submits to, before it is resolved.
+type extractedResource struct {
+	href      string // the reference as written in the document
+	url       string // href resolved against a <base> tag, if any
+	tag       string
+	attribute string
+	kind      ResourceType
+}
+
+// resourceAttribute names the attribute through which a tag loads a subresource.
+type resourceAttribute struct {
+	name string
+	kind ResourceType
+}
+
+// resourceAttributes lists the attributes that make a tag load a subresource.
+// <link> is handled separately because what it loads depends on its rel.
+var resourceAttributes = map[string][]resourceAttribute{
+	"script": {{"src", ResourceScript}},
+	"iframe": {{"src", ResourceFrame}},
+	"frame":  {{"src", ResourceFrame}},
+	"object": {{"data", ResourceObject}},
+	"embed":  {{"src", ResourceObject}},
+	"form":   {{"action", ResourceForm}},
+	"img":    {{"src", ResourceImage}, {"srcset", ResourceImage}},
+	"input":  {{"src", ResourceImage}},
+	"video":  {{"src", ResourceMedia}, {"poster", ResourceImage}},
+	"audio":  {{"src", ResourceMedia}},
+	"track":  {{"src", ResourceMedia}},
+	// <source> selects media inside <video> and <audio>, and images through srcset inside <picture>
+	"source": {{"src", ResourceMedia}, {"srcset", ResourceImage}},
+}
+
+// linkResources maps the rel values of <link> that load a subresource to its type.
+var linkResources = map[string]ResourceType{
+	"stylesheet":       ResourceStylesheet,
+	"icon":             ResourceImage,
+	"apple-touch-icon": ResourceImage,
+	"manifest":         ResourceOther,
+}
+
+// cssURLPattern matches url(...) references and the string form of @import in CSS.
+var cssURLPattern = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)'"\s]*))\s*\)|@import\s+(?:"([^"]*)"|'([^']*)')`)
+
+// resourceCollector gathers the subresources of the document, honoring a preceding <base> tag:
+// tag attributes, srcset candidates, and url() references in <style> blocks and style attributes.
+type resourceCollector struct {
+	baseParsed *url.URL
+	resources  []extractedResource
+	inStyle    bool
+}
+
+func (c *resourceCollector) collect(tokenType html.TokenType, token html.Token) {
+	switch tokenType {
+	case html.TextToken:
+		if c.inStyle {
+			c.addCSS(token.Data, "style", "")
+		}
+		return
+	case html.EndTagToken:
+		if token.Data == "style" {
+			c.inStyle = false
+		}
+		return
+	case html.StartTagToken, html.SelfClosingTagToken:
+	default:
+		return
+	}
+
+	if style := getAttributeValue(token, "style"); style != "" {
+		c.addCSS(style, token.Data, "style")
+	}
+
+	switch token.Data {
+	case "base":
+		c.baseParsed = extractBaseHref(token)
+		return
+	case "style":
+		c.inStyle = tokenType == html.StartTagToken
+		return
+	case "link":
+		for _, rel := range strings.Fields(strings.ToLower(getAttributeValue(token, "rel"))) {
+			if kind, ok := linkResources[rel]; ok {
+				c.add(getAttributeValue(token, "href"), token.Data, "href", kind)
+				return
+			}
+		}
+		return
+	}
+
+	for _, attribute := range resourceAttributes[token.Data] {
+		value := getAttributeValue(token, attribute.name)
+		if attribute.name == "srcset" {
+			for _, candidate := range parseSrcset(value) {
+				c.add(candidate, token.Data, attribute.name, attribute.kind)
+			}
+			continue
+		}
+		c.add(value, token.Data, attribute.name, attribute.kind)
+	}
+}
+
+// addCSS adds every url() and @import reference in a stylesheet or style attribute.
+func (c *resourceCollector) addCSS(css, tag, attribute string) {
+	for _, match := range cssURLPattern.FindAllStringSubmatch(css, -1) {
+		kind := ResourceStylesheet
+		reference := strings.Join(match[4:], "")
+		if reference == "" {
+			reference = strings.Join(match[1:4], "")
+			kind = cssResourceType(reference)
+		}
+		c.add(reference, tag, attribute, kind)
+	}
+}
+
+// add records a reference to an HTTP resource; data:, javascript: and other schemes are skipped.
+func (c *resourceCollector) add(href, tag, attribute string, kind ResourceType) {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") {
+		return
+	}
+
+	parsed, err := url.Parse(href)
+	if err != nil {
+		return
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "", "http", "https":
+	default:
+		return
+	}
+
+	resolved := href
+	if c.baseParsed != nil && !parsed.IsAbs() {
+		resolved = c.baseParsed.ResolveReference(parsed).String()
+	}
+	c.resources = append(c.resources, extractedResource{href: href, url: resolved, tag: tag, attribute: attribute, kind: kind})
+}
+
+// parseSrcset returns the URLs of the candidates of a srcset attribute, such as "a.png 1x, b.png 2x".
+// As in browsers, a URL is delimited by whitespace, so commas inside data: URLs do not split it.
+func parseSrcset(srcset string) []string {
+	var urls []string
+	rest := srcset
+	for {
+		rest = strings.TrimLeft(rest, " \t\n\r\f,")
+		if rest == "" {
+			return urls
+		}
+
+		end := strings.IndexAny(rest, " \t\n\r\f")
+		if end < 0 {
+			end = len(rest)
+		}
+		candidate := rest[:end]
+		rest = rest[end:]
+
+		if trimmed := strings.TrimRight(candidate, ","); trimmed != candidate {
+			candidate = trimmed
+		} else {
+			rest = skipDescriptors(rest)
+		}
+		urls = append(urls, candidate)
+	}
+}
+
+// skipDescriptors drops the descriptors of a srcset candidate up to the comma that ends it.
+func skipDescriptors(rest string) string {
+	depth := 0
+	for index, char := range rest {
+		switch char {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				return rest[index+1:]
+			}
+		}
+	}
+	return ""
+}
+
+// seoCollector gathers the metadata search engines read from the document: the number of <title>
+// elements, the description, viewport and robots meta tags, and the canonical and hreflang links,
+// honoring a preceding <base> tag.
+type seoCollector struct {
+	baseParsed   *url.URL
+	titles       int
+	descriptions []string
+	viewports    []string
+	directives   []IndexingDirective
+	canonicals   []string
+	alternates   []Alternate
+	// svgDepth counts the open <svg> elements, whose <title> describes a graphic rather than the page
+	svgDepth int
+}
+
+func (c *seoCollector) collect(tokenType html.TokenType, token html.Token) {
+	switch tokenType {
+	case html.EndTagToken:
+		if token.Data == "svg" && c.svgDepth > 0 {
+			c.svgDepth--
+		}
+		return
+	case html.StartTagToken, html.SelfClosingTagToken:
+	default:
+		return
+	}
+
+	switch token.Data {
+	case "base":
+		c.baseParsed = extractBaseHref(token)
+	case "svg":
+		if tokenType == html.StartTagToken {
+			c.svgDepth++
+		}
+	case "title":
+		if c.svgDepth == 0 {
+			c.titles++
+		}
+	case "meta":
+		content := strings.TrimSpace(getAttributeValue(token, "content"))
+		switch name := strings.ToLower(strings.TrimSpace(getAttributeValue(token, "name"))); name {
+		case "description":
+			c.descriptions = append(c.descriptions, content)
+		case "viewport":
+			c.viewports = append(c.viewports, content)
+		case "robots":
+			c.directives = append(c.directives, IndexingDirective{Source: DirectiveMeta, Directives: splitDirectives(content)})
+		case "googlebot":
+			c.directives = append(c.directives, IndexingDirective{Source: DirectiveMeta, UserAgent: name, Directives: splitDirectives(content)})
+		}
+	case "link":
+		href := strings.TrimSpace(getAttributeValue(token, "href"))
+		if href == "" {
+			return
+		}
+		if parsed, err := url.Parse(href); err == nil && c.baseParsed != nil {
+			href = c.baseParsed.ResolveReference(parsed).String()
+		}
+		rels := strings.Fields(strings.ToLower(getAttributeValue(token, "rel")))
+		hreflang := strings.TrimSpace(getAttributeValue(token, "hreflang"))
+		switch {
+		case slices.Contains(rels, "canonical"):
+			c.canonicals = append(c.canonicals, href)
+		case slices.Contains(rels, "alternate") && hreflang != "":
+			c.alternates = append(c.alternates, Alternate{HrefLang: hreflang, URL: href})
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+// sniffLength is how many bytes are inspected to detect the content type when the server does not declare one.
+const sniffLength = 512
+
+// Page is a fetched HTML document.
+type Page struct {
+	// URL is where the document was served from after following Redirects.
+	URL       string
+	Redirects []RedirectHop
+	Response  ResponseInfo
+	// TLS is nil for pages served over plain HTTP.
+	TLS *TLSInfo
+	// Body is the document transcoded to UTF-8.
+	Body        string
+	ContentType string
+	// Encoding is the name of the character set the document was decoded from.
+	Encoding string
+	// Truncated is set when the body exceeded the analyzer's MaxBodySize and was cut short.
+	Truncated bool
+}
+
+// StatusError is returned by FetchHTML when the target page responds with a non-2xx status code.
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "received non-2xx status code: " + e.Status
+}
+
+// ContentTypeError is returned by FetchHTML when the target is not an HTML document.
+type ContentTypeError struct {
+	ContentType string
+}
+
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf("unsupported content type %q: only HTML pages can be analyzed", e.ContentType)
+}
+
+// FetchHTML fetches the HTML content of the page, following and recording redirects.
+// The request is aborted as soon as ctx is cancelled. Responses that are not HTML are rejected
+// before their body is read, and at most MaxBodySize bytes are read.
+func (analyser *DefaultAnalyzer) FetchHTML(ctx context.Context, targetURL string) (*Page, error) {
+	header := http.Header{}
+	header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	timer := &requestTimer{}
+	resp, hops, err := analyser.follow(ctx, http.MethodGet, targetURL, header, timer)
+	if err != nil {
+		return nil, certificateError(err, time.Now())
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !isHTMLContentType(contentType) {
+		return nil, &ContentTypeError{ContentType: contentType}
+	}
+
+	body, truncated, err := readLimited(resp.Body, analyser.MaxBodySize)
+	if err != nil {
+		return nil, errors.New("failed to read response body: " + err.Error())
+	}
+	timer.finish()
+	response := newResponseInfo(resp)
+	response.Timing = timer.timing()
+
+	// Servers that omit Content-Type get the same sniffing a browser would apply
+	if contentType == "" {
+		contentType = http.DetectContentType(body[:min(len(body), sniffLength)])
+		if !isHTMLContentType(contentType) {
+			return nil, &ContentTypeError{ContentType: contentType}
+		}
+	}
+
+	decoded, encoding := decodeBody(body, contentType)
+	var tlsInfo *TLSInfo
+	if resp.TLS != nil {
+		tlsInfo = inspectConnection(resp.TLS, resp.Request.URL.Hostname(), time.Now())
+	}
+
+	return &Page{
+		URL:         finalURL(resp),
+		Redirects:   hops,
+		Response:    response,
+		TLS:         tlsInfo,
+		Body:        decoded,
+		ContentType: contentType,
+		Encoding:    encoding,
+		Truncated:   truncated,
+	}, nil
+}
+
+// decodeBody transcodes the raw body to UTF-8 using, in order of precedence, a byte order mark,
+// the charset parameter of the Content-Type header, and a <meta charset> or <meta http-equiv> tag.
+// It returns the decoded body and the name of the detected encoding.
+func decodeBody(body []byte, contentType string) (string, string) {
+	encoding, name, certain := charset.DetermineEncoding(body, contentType)
+
+	// Without any declaration the detector falls back to windows-1252 if the first kilobyte
+	// is plain ASCII; a body that is valid UTF-8 throughout is far more likely to be UTF-8.
+	if !certain && name == "windows-1252" {
+		if complete := trimIncompleteRune(body); utf8.Valid(complete) {
+			return string(complete), "utf-8"
+		}
+	}
+
+	decoded, err := encoding.NewDecoder().Bytes(body)
+	if err != nil {
+		return string(body), name
+	}
+	return strings.TrimPrefix(string(decoded), "\ufeff"), name
+}
+
+// readLimited reads at most limit bytes and reports whether more data was available.
+// A limit of zero or less reads everything.
+func readLimited(reader io.Reader, limit int64) ([]byte, bool, error) {
+	if limit <= 0 {
+		body, err := io.ReadAll(reader)
+		return body, false, err
+	}
+
+	// Read one byte past the limit to tell an exactly-sized body from a larger one
+	body, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(body)) > limit {
+		return body[:limit], true, nil
+	}
+	return body, false, nil
+}
+
+// isHTMLContentType reports whether a Content-Type header value describes an HTML document.
+func isHTMLContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// trimIncompleteRune drops a multi-byte UTF-8 sequence cut off at the end of body, as happens when a page is truncated.
+func trimIncompleteRune(body []byte) []byte {
+	for i := len(body) - 1; i >= 0 && i >= len(body)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(body[i]) {
+			if !utf8.FullRune(body[i:]) {
+				return body[:i]
+			}
+			break
+		}
+	}
+	return body
+}
@@ -0,0 +1,171 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedResponseClient returns a 200 response with the given Content-Type header and body for every request.
+func fixedResponseClient(contentType, body string) *mockHTTPClient {
+	return &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			if contentType != "" {
+				header.Set("Content-Type", contentType)
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}, nil
+		},
+	}
+}
+
+func TestFetchHTML_ContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expectError bool
+	}{
+		{"HTML with charset", "text/html; charset=utf-8", "<html></html>", false},
+		{"XHTML", "application/xhtml+xml", "<html></html>", false},
+		{"sniffed HTML", "", "<!DOCTYPE html><html></html>", false},
+		{"video", "video/mp4", "binary", true},
+		{"JSON", "application/json", `{"a":1}`, true},
+		{"sniffed binary", "", "\x00\x01\x02\x03PK", true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(fixedResponseClient(testCase.contentType, testCase.body))
+			page, err := analyzer.FetchHTML(context.Background(), "http://example.com")
+
+			if !testCase.expectError {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.body, page.Body)
+				return
+			}
+
+			var contentTypeErr *ContentTypeError
+			assert.True(t, errors.As(err, &contentTypeErr), "expected ContentTypeError, got %v", err)
+		})
+	}
+}
+
+func TestFetchHTML_MaxBodySize(t *testing.T) {
+	body := "<html><body>" + strings.Repeat("a", 100) + "</body></html>"
+
+	tests := []struct {
+		name         string
+		limit        int64
+		expectedSize int
+		truncated    bool
+	}{
+		{"no limit", 0, len(body), false},
+		{"limit above size", int64(len(body)) + 1, len(body), false},
+		{"limit equal to size", int64(len(body)), len(body), false},
+		{"limit below size", 20, 20, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			analyser := &DefaultAnalyzer{Client: fixedResponseClient("text/html", body), MaxBodySize: testCase.limit}
+			page, err := analyser.FetchHTML(context.Background(), "http://example.com")
+
+			assert.NoError(t, err)
+			assert.Len(t, page.Body, testCase.expectedSize)
+			assert.Equal(t, testCase.truncated, page.Truncated)
+		})
+	}
+}
+
+func TestAnalyze_ReportsTruncation(t *testing.T) {
+	body := "<html><head><title>Big</title></head><body>" + strings.Repeat("<p>filler</p>", 100) + "</body></html>"
+	analyser := &DefaultAnalyzer{Client: fixedResponseClient("text/html", body), MaxBodySize: 64}
+
+	report, err := analyser.Analyze(context.Background(), "http://example.com")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Big", report.Title)
+	assert.Equal(t, PageInfo{ContentType: "text/html", Encoding: "utf-8", Size: 64, Truncated: true}, report.Page)
+}
+
+func TestFetchHTML_DecodesCharset(t *testing.T) {
+	tests := []struct {
+		name             string
+		contentType      string
+		body             string
+		expectedTitle    string
+		expectedEncoding string
+	}{
+		{
+			name:             "Shift_JIS from header",
+			contentType:      "text/html; charset=Shift_JIS",
+			body:             "<html><head><title>\x93\xfa\x96\x7b\x8c\xea</title></head></html>",
+			expectedTitle:    "日本語",
+			expectedEncoding: "shift_jis",
+		},
+		{
+			name:             "windows-1251 from meta charset",
+			contentType:      "text/html",
+			body:             "<html><head><meta charset=\"windows-1251\"><title>\xcf\xf0\xe8\xe2\xe5\xf2</title></head></html>",
+			expectedTitle:    "Привет",
+			expectedEncoding: "windows-1251",
+		},
+		{
+			name:             "ISO-8859-1 from meta http-equiv",
+			contentType:      "text/html",
+			body:             "<html><head><meta http-equiv=\"Content-Type\" content=\"text/html; charset=ISO-8859-1\"><title>Caf\xe9</title></head></html>",
+			expectedTitle:    "Café",
+			expectedEncoding: "windows-1252",
+		},
+		{
+			name:             "UTF-8 byte order mark overrides header",
+			contentType:      "text/html; charset=windows-1251",
+			body:             "\xef\xbb\xbf<html><head><title>Café</title></head></html>",
+			expectedTitle:    "Café",
+			expectedEncoding: "utf-8",
+		},
+		{
+			name:             "undeclared UTF-8",
+			contentType:      "text/html",
+			body:             "<html><head><title>Café</title></head></html>",
+			expectedTitle:    "Café",
+			expectedEncoding: "utf-8",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(fixedResponseClient(testCase.contentType, testCase.body))
+			page, err := analyzer.FetchHTML(context.Background(), "http://example.com")
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedEncoding, page.Encoding)
+			assert.Equal(t, testCase.expectedTitle, analyzer.ExtractTitle(page.Body))
+			assert.False(t, strings.HasPrefix(page.Body, "\ufeff"))
+		})
+	}
+}
+
+func TestFetchHTML_TruncatedUTF8(t *testing.T) {
+	body := "<html><head><title>Ünïcödé</title></head></html>"
+	// Cut the body in the middle of the two-byte "Ü"
+	limit := int64(strings.Index(body, "Ü") + 1)
+	analyser := &DefaultAnalyzer{Client: fixedResponseClient("text/html", body), MaxBodySize: limit}
+
+	page, err := analyser.FetchHTML(context.Background(), "http://example.com")
+
+	assert.NoError(t, err)
+	assert.True(t, page.Truncated)
+	assert.Equal(t, "utf-8", page.Encoding)
+	assert.Equal(t, "<html><head><title>", page.Body)
+}
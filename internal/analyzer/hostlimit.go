@@ -0,0 +1,249 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHostConcurrency is the number of link checks NewAnalyzer lets run against one host at a time.
+	DefaultHostConcurrency = 2
+	// DefaultMaxRetryAfter is the longest Retry-After NewAnalyzer waits for before retrying a link.
+	DefaultMaxRetryAfter = 30 * time.Second
+	// DefaultMaxCrawlDelay is the longest robots.txt Crawl-delay NewAnalyzer keeps to between requests.
+	DefaultMaxCrawlDelay = 10 * time.Second
+)
+
+// HostLimits configures how politely a HostLimiter treats each host.
+type HostLimits struct {
+	// Concurrency is the number of requests in flight to one host at a time. Zero means no cap.
+	Concurrency int
+	// RequestsPerSecond spaces out the start of requests to one host. Zero means no limit.
+	RequestsPerSecond float64
+	// MaxRetryAfter is the longest Retry-After a 429 or 503 response may ask for and still be
+	// waited for and retried. Zero means such responses are never retried.
+	MaxRetryAfter time.Duration
+	// MaxCrawlDelay caps the Crawl-delay of robots.txt that requests to a host are spaced out by,
+	// so a site cannot stall an analysis. Zero means Crawl-delay is ignored.
+	MaxCrawlDelay time.Duration
+}
+
+// HostLimiter caps the concurrency and request rate of link checks per host, and pauses a host that
+// answered 429 or 503 with a Retry-After. It is safe for concurrent use and is meant to be shared by
+// every analysis, so that concurrent analyses of the same site are polite together.
+// A nil *HostLimiter applies no limits.
+type HostLimiter struct {
+	limits HostLimits
+	mutex  sync.Mutex
+	hosts  map[string]*hostState
+}
+
+type hostState struct {
+	// slots holds one token per request in flight; it is nil when concurrency is not capped
+	slots chan struct{}
+	// next is the earliest time the next request may start
+	next time.Time
+	// interval is the Crawl-delay of the host, if it asks for one
+	interval time.Duration
+	// users counts the requests holding or waiting for a slot, so idle hosts can be forgotten
+	users int
+}
+
+// NewHostLimiter creates a HostLimiter that applies limits to each host separately.
+func NewHostLimiter(limits HostLimits) *HostLimiter {
+	return &HostLimiter{limits: limits, hosts: make(map[string]*hostState)}
+}
+
+// concurrency returns the per-host concurrency cap, or zero if there is none.
+func (limiter *HostLimiter) concurrency() int {
+	if limiter == nil {
+		return 0
+	}
+	return limiter.limits.Concurrency
+}
+
+// acquire blocks until a request to host may start and returns the function that ends it.
+// It fails only when ctx is done first.
+func (limiter *HostLimiter) acquire(ctx context.Context, host string) (release func(), err error) {
+	if limiter == nil {
+		return func() {}, nil
+	}
+
+	state := limiter.join(host)
+	leave := func() {
+		limiter.mutex.Lock()
+		state.users--
+		limiter.mutex.Unlock()
+	}
+
+	if state.slots != nil {
+		select {
+		case state.slots <- struct{}{}:
+		case <-ctx.Done():
+			leave()
+			return nil, ctx.Err()
+		}
+	}
+	release = func() {
+		if state.slots != nil {
+			<-state.slots
+		}
+		leave()
+	}
+
+	if err := sleep(ctx, limiter.reserve(state)); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// wait blocks until another request to host may start, for a caller that already holds one of its slots.
+func (limiter *HostLimiter) wait(ctx context.Context, host string) error {
+	if limiter == nil {
+		return nil
+	}
+	limiter.mutex.Lock()
+	state := limiter.hosts[host]
+	limiter.mutex.Unlock()
+	if state == nil {
+		return nil
+	}
+	return sleep(ctx, limiter.reserve(state))
+}
+
+// backOff holds back every request to host for delay, as asked by a Retry-After header.
+func (limiter *HostLimiter) backOff(host string, delay time.Duration) {
+	if limiter == nil {
+		return
+	}
+	until := time.Now().Add(delay)
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	if state := limiter.hosts[host]; state != nil && until.After(state.next) {
+		state.next = until
+	}
+}
+
+// crawlDelay spaces out requests to host by delay, as asked by its robots.txt, for a caller that
+// holds one of its slots. The request of the caller counts as the last one the delay is kept from.
+func (limiter *HostLimiter) crawlDelay(host string, delay time.Duration) {
+	if limiter == nil {
+		return
+	}
+	delay = min(delay, limiter.limits.MaxCrawlDelay)
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	state := limiter.hosts[host]
+	if state == nil || state.interval == delay {
+		return
+	}
+	state.interval = delay
+	if next := time.Now().Add(delay); next.After(state.next) {
+		state.next = next
+	}
+}
+
+// honors reports whether a Retry-After of delay should be waited for and the request retried.
+func (limiter *HostLimiter) honors(delay time.Duration) bool {
+	return limiter != nil && delay <= limiter.limits.MaxRetryAfter
+}
+
+// join returns the state of host, creating it if needed, and counts the caller as one of its users.
+func (limiter *HostLimiter) join(host string) *hostState {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	state := limiter.hosts[host]
+	if state == nil {
+		limiter.forgetIdle(time.Now())
+		state = &hostState{}
+		if limiter.limits.Concurrency > 0 {
+			state.slots = make(chan struct{}, limiter.limits.Concurrency)
+		}
+		limiter.hosts[host] = state
+	}
+	state.users++
+	return state
+}
+
+// forgetIdle drops hosts nobody is using and that no longer hold requests back, so the map stays small
+// in a long-running server. The caller must hold the mutex.
+func (limiter *HostLimiter) forgetIdle(now time.Time) {
+	for host, state := range limiter.hosts {
+		if state.users == 0 && !state.next.After(now) {
+			delete(limiter.hosts, host)
+		}
+	}
+}
+
+// reserve books the next start time of host and returns how long the caller has to wait for it.
+func (limiter *HostLimiter) reserve(state *hostState) time.Duration {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := time.Now()
+	start := now
+	if state.next.After(start) {
+		start = state.next
+	}
+	interval := state.interval
+	if limiter.limits.RequestsPerSecond > 0 {
+		interval = max(interval, time.Duration(float64(time.Second)/limiter.limits.RequestsPerSecond))
+	}
+	if interval > 0 {
+		state.next = start.Add(interval)
+	}
+	return start.Sub(now)
+}
+
+// sleep waits for delay, returning early with the context's error if ctx is done first.
+func sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// hostKey returns the host, with any port, that requests to rawURL are limited under.
+func hostKey(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Host)
+}
+
+// retryAfter returns how long a 429 or 503 response asks clients to wait before trying again.
+// The Retry-After header may hold either a number of seconds or an HTTP date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,224 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckLinks_CapsConcurrencyPerHost(t *testing.T) {
+	var body strings.Builder
+	for i := 0; i < 8; i++ {
+		fmt.Fprintf(&body, `<a href="https://one.example/%d">One</a><a href="https://two.example/%d">Two</a>`, i, i)
+	}
+
+	var mutex sync.Mutex
+	inFlight := map[string]int{}
+	peak := map[string]int{}
+	total, peakTotal := 0, 0
+	client := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			mutex.Lock()
+			inFlight[req.URL.Host]++
+			total++
+			peak[req.URL.Host] = max(peak[req.URL.Host], inFlight[req.URL.Host])
+			peakTotal = max(peakTotal, total)
+			mutex.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mutex.Lock()
+			inFlight[req.URL.Host]--
+			total--
+			mutex.Unlock()
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("OK"))}, nil
+		},
+	}
+
+	analyser := NewAnalyzer(client).(*DefaultAnalyzer)
+	analyser.Hosts = NewHostLimiter(HostLimits{Concurrency: 2})
+	_, external, broken, err := analyser.AnalyzeLinks(context.Background(), body.String(), "https://example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, 16, external)
+	assert.Zero(t, broken)
+	assert.Equal(t, 2, peak["one.example"])
+	assert.Equal(t, 2, peak["two.example"])
+	assert.Greater(t, peakTotal, 2, "hosts should be checked in parallel with each other")
+}
+
+func TestCheckLinks_RetriesDoNotHoldWorkers(t *testing.T) {
+	var links []extractedLink
+	for i := 0; i < maxWorkers; i++ {
+		link := fmt.Sprintf("https://flaky.example/%d", i)
+		links = append(links, extractedLink{href: link, link: link, tag: "a"})
+	}
+	links = append(links, extractedLink{href: "https://other.example/", link: "https://other.example/", tag: "a"})
+
+	var mutex sync.Mutex
+	var otherStarted time.Time
+	client := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Host == "other.example" {
+				mutex.Lock()
+				otherStarted = time.Now()
+				mutex.Unlock()
+				return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("OK"))}, nil
+			}
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}, nil
+		},
+	}
+
+	analyser := NewAnalyzer(client).(*DefaultAnalyzer)
+	analyser.Hosts = NewHostLimiter(HostLimits{})
+	// Hold the other host back until the flaky one has taken every worker
+	analyser.Hosts.hosts["other.example"] = &hostState{next: time.Now().Add(50 * time.Millisecond)}
+	analyser.Retries = 1
+	analyser.RetryBackoff = 500 * time.Millisecond
+	start := time.Now()
+	results, err := analyser.checkLinks(context.Background(), links, "https://example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, results[0].Attempts)
+	assert.Equal(t, 200, results[maxWorkers].StatusCode)
+	assert.Less(t, otherStarted.Sub(start), 250*time.Millisecond, "other hosts should be checked while the flaky one backs off")
+}
+
+func TestHostLimiter_RequestsPerSecond(t *testing.T) {
+	limiter := NewHostLimiter(HostLimits{RequestsPerSecond: 20})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := limiter.acquire(context.Background(), "example.com")
+		require.NoError(t, err)
+		release()
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	// Other hosts are not held back by the first one
+	start = time.Now()
+	release, err := limiter.acquire(context.Background(), "other.example")
+	require.NoError(t, err)
+	release()
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestHostLimiter_AcquireCancelled(t *testing.T) {
+	limiter := NewHostLimiter(HostLimits{Concurrency: 1})
+	release, err := limiter.acquire(context.Background(), "example.com")
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = limiter.acquire(ctx, "example.com")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAnalyze_HonorsRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		requests   int32
+		statusCode int
+	}{
+		{"retried after the wait", "1", 2, 200},
+		{"longer than allowed", "3600", 1, 429},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var requests int32
+			client := &mockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if atomic.AddInt32(&requests, 1) == 1 {
+						header := http.Header{"Retry-After": {testCase.retryAfter}}
+						return &http.Response{StatusCode: http.StatusTooManyRequests, Header: header, Body: io.NopCloser(strings.NewReader(""))}, nil
+					}
+					return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("OK"))}, nil
+				},
+			}
+
+			analyser := NewAnalyzer(client).(*DefaultAnalyzer)
+			start := time.Now()
+			results, err := analyser.checkLinks(context.Background(), []extractedLink{{href: "/busy", link: "/busy", tag: "a"}}, "https://example.com")
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.requests, atomic.LoadInt32(&requests))
+			assert.Equal(t, testCase.statusCode, results[0].StatusCode)
+			if testCase.requests > 1 {
+				assert.GreaterOrEqual(t, time.Since(start), time.Second)
+			}
+		})
+	}
+}
+
+func TestAnalyze_RetryAfterPausesHostWithoutRetries(t *testing.T) {
+	var mutex sync.Mutex
+	started := map[string]time.Time{}
+	client := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			mutex.Lock()
+			started[req.URL.Path] = time.Now()
+			mutex.Unlock()
+			if req.URL.Path == "/busy" {
+				header := http.Header{"Retry-After": {"1"}}
+				return &http.Response{StatusCode: http.StatusTooManyRequests, Header: header, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("OK"))}, nil
+		},
+	}
+
+	analyser := NewAnalyzer(client).(*DefaultAnalyzer)
+	analyser.Retries = 0
+	analyser.Hosts = NewHostLimiter(HostLimits{Concurrency: 1, MaxRetryAfter: DefaultMaxRetryAfter})
+	links := []extractedLink{{href: "/busy", link: "/busy", tag: "a"}, {href: "/next", link: "/next", tag: "a"}}
+	results, err := analyser.checkLinks(context.Background(), links, "https://example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeRateLimited, results[0].Outcome)
+	assert.Equal(t, 1, results[0].Attempts)
+	assert.Equal(t, 200, results[1].StatusCode)
+	assert.GreaterOrEqual(t, started["/next"].Sub(started["/busy"]), time.Second)
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		statusCode int
+		value      string
+		expected   time.Duration
+		ok         bool
+	}{
+		{"seconds", 429, "5", 5 * time.Second, true},
+		{"HTTP date", 503, "Wed, 01 Jan 2025 12:00:30 GMT", 30 * time.Second, true},
+		{"date in the past", 503, "Wed, 01 Jan 2025 11:00:00 GMT", 0, true},
+		{"missing", 429, "", 0, false},
+		{"negative", 429, "-1", 0, false},
+		{"garbage", 429, "soon", 0, false},
+		{"other status", 500, "5", 0, false},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: testCase.statusCode, Header: http.Header{}}
+			if testCase.value != "" {
+				resp.Header.Set("Retry-After", testCase.value)
+			}
+			delay, ok := retryAfter(resp, now)
+			assert.Equal(t, testCase.ok, ok)
+			assert.Equal(t, testCase.expected, delay)
+		})
+	}
+}
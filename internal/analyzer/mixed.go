@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+)
+
+// MixedContentReport lists the resources an HTTPS page loads, or submits forms to, over plain HTTP.
+type MixedContentReport struct {
+	// Checked is false when the page itself was not served over HTTPS, so mixed content does not apply.
+	Checked bool `json:"checked"`
+	// Active mixed content, such as scripts, frames, stylesheets and form targets, is blocked
+	// by browsers and could otherwise take over the page.
+	Active []MixedResource `json:"active"`
+	// Passive mixed content, such as images and media, is displayed but can be observed or replaced in transit.
+	Passive []MixedResource `json:"passive"`
+}
+
+// MixedResource is a plain HTTP resource referenced by an HTTPS page.
+type MixedResource struct {
+	Tag       string `json:"tag"`
+	Attribute string `json:"attribute"`
+	URL       string `json:"url"`
+}
+
+// findMixedContent resolves the resources against the page URL and lists those loaded over plain HTTP.
+func findMixedContent(resources []extractedResource, pageURL string) MixedContentReport {
+	base, err := url.Parse(pageURL)
+	if err != nil || !strings.EqualFold(base.Scheme, "https") {
+		return MixedContentReport{}
+	}
+
+	report := MixedContentReport{Checked: true}
+	for _, resource := range resources {
+		parsed, err := url.Parse(resource.url)
+		if err != nil {
+			continue
+		}
+		resolved := base.ResolveReference(parsed)
+		if !strings.EqualFold(resolved.Scheme, "http") {
+			continue
+		}
+
+		mixed := MixedResource{Tag: resource.tag, Attribute: resource.attribute, URL: resolved.String()}
+		if resource.kind.passive() {
+			report.Passive = append(report.Passive, mixed)
+		} else {
+			report.Active = append(report.Active, mixed)
+		}
+	}
+	return report
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const mixedContentPage = `<!DOCTYPE html>
+<html>
+<head>
+	<link rel="stylesheet" href="http://cdn.example.com/site.css">
+	<link rel="icon" href="http://cdn.example.com/favicon.ico">
+	<link rel="preconnect" href="http://cdn.example.com">
+	<script src="http://cdn.example.com/app.js"></script>
+	<script src="//cdn.example.com/safe.js"></script>
+</head>
+<body>
+	<img src="http://images.example.com/a.png">
+	<img src="/local.png">
+	<iframe src="http://widgets.example.com/embed"></iframe>
+	<video src="http://media.example.com/clip.mp4"><track src="http://media.example.com/captions.vtt"></video>
+	<audio><source src="http://media.example.com/song.mp3"></audio>
+	<object data="http://plugins.example.com/movie.swf"></object>
+	<form action="http://secure.example.com/login"><input type="password"></form>
+	<a href="http://example.org/">Plain links are not mixed content</a>
+</body>
+</html>`
+
+func TestFindMixedContent(t *testing.T) {
+	resources := &resourceCollector{}
+	walkDocument(mixedContentPage, resources)
+
+	report := findMixedContent(resources.resources, "https://example.com/page")
+
+	assert.True(t, report.Checked)
+	assert.Equal(t, []MixedResource{
+		{Tag: "link", Attribute: "href", URL: "http://cdn.example.com/site.css"},
+		{Tag: "script", Attribute: "src", URL: "http://cdn.example.com/app.js"},
+		{Tag: "iframe", Attribute: "src", URL: "http://widgets.example.com/embed"},
+		{Tag: "object", Attribute: "data", URL: "http://plugins.example.com/movie.swf"},
+		{Tag: "form", Attribute: "action", URL: "http://secure.example.com/login"},
+	}, report.Active)
+	assert.Equal(t, []MixedResource{
+		{Tag: "link", Attribute: "href", URL: "http://cdn.example.com/favicon.ico"},
+		{Tag: "img", Attribute: "src", URL: "http://images.example.com/a.png"},
+		{Tag: "video", Attribute: "src", URL: "http://media.example.com/clip.mp4"},
+		{Tag: "track", Attribute: "src", URL: "http://media.example.com/captions.vtt"},
+		{Tag: "source", Attribute: "src", URL: "http://media.example.com/song.mp3"},
+	}, report.Passive)
+}
+
+func TestFindMixedContent_PlainHTTPPage(t *testing.T) {
+	resources := &resourceCollector{}
+	walkDocument(mixedContentPage, resources)
+
+	report := findMixedContent(resources.resources, "http://example.com/page")
+
+	assert.Equal(t, MixedContentReport{}, report)
+}
+
+func TestFindMixedContent_BaseTag(t *testing.T) {
+	resources := &resourceCollector{}
+	walkDocument(`<base href="http://static.example.com/"><script src="app.js"></script><img src="https://static.example.com/ok.png">`, resources)
+
+	report := findMixedContent(resources.resources, "https://example.com/")
+
+	assert.Equal(t, []MixedResource{{Tag: "script", Attribute: "src", URL: "http://static.example.com/app.js"}}, report.Active)
+	assert.Empty(t, report.Passive)
+}
+
+func TestAnalyze_ReportsMixedContent(t *testing.T) {
+	analyser := NewAnalyzer(fixedResponseClient("text/html", mixedContentPage))
+
+	report, err := analyser.Analyze(context.Background(), "https://example.com/page")
+
+	assert.NoError(t, err)
+	assert.True(t, report.MixedContent.Checked)
+	assert.Len(t, report.MixedContent.Active, 5)
+	assert.Len(t, report.MixedContent.Passive, 5)
+}
@@ -0,0 +1,132 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gayansanjeewa/gogeturl/internal/netguard"
+)
+
+// Outcome classifies the result of checking a link.
+type Outcome string
+
+const (
+	OutcomeOK Outcome = "ok"
+	// OutcomeRedirect means the link works but only after redirecting elsewhere.
+	OutcomeRedirect    Outcome = "redirect"
+	OutcomeClientError Outcome = "client_error"
+	OutcomeServerError Outcome = "server_error"
+	OutcomeTimeout     Outcome = "timeout"
+	OutcomeDNSFailure  Outcome = "dns_failure"
+	OutcomeTLSError    Outcome = "tls_error"
+	// OutcomeRateLimited means the server kept answering 429 Too Many Requests.
+	OutcomeRateLimited Outcome = "rate_limited"
+	// OutcomeBlockedByRobots means the link was not requested because robots.txt disallows it.
+	OutcomeBlockedByRobots Outcome = "blocked_by_robots"
+	// OutcomeNetworkError covers any other failure to get a response, such as a refused connection.
+	OutcomeNetworkError Outcome = "network_error"
+	// OutcomeInvalidURL means the link could not be parsed and was never requested.
+	OutcomeInvalidURL Outcome = "invalid_url"
+)
+
+const (
+	// DefaultRetries is the number of times NewAnalyzer retries a link after a transient failure.
+	DefaultRetries = 2
+	// DefaultRetryBackoff is the wait NewAnalyzer starts with before a retry; it doubles on every attempt.
+	DefaultRetryBackoff = 500 * time.Millisecond
+	// maxRetryBackoff caps the exponential backoff between two attempts.
+	maxRetryBackoff = 10 * time.Second
+)
+
+// Broken reports whether the outcome means the link does not work. Timeouts and rate limiting
+// only mean it could not be verified, and links skipped for robots.txt were never requested.
+func (outcome Outcome) Broken() bool {
+	switch outcome {
+	case OutcomeOK, OutcomeRedirect, OutcomeTimeout, OutcomeRateLimited, OutcomeBlockedByRobots:
+		return false
+	}
+	return true
+}
+
+// classifyOutcome describes the final attempt at a link: the status code it was answered with
+// and whether it redirected, or the error that kept it from being answered at all.
+func classifyOutcome(statusCode int, redirected bool, err error) Outcome {
+	if err != nil {
+		return classifyError(err)
+	}
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return OutcomeRateLimited
+	case statusCode >= 500:
+		return OutcomeServerError
+	case statusCode >= 400:
+		return OutcomeClientError
+	case redirected:
+		return OutcomeRedirect
+	}
+	return OutcomeOK
+}
+
+func classifyError(err error) Outcome {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return OutcomeTimeout
+		}
+		return OutcomeDNSFailure
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return OutcomeTimeout
+	}
+
+	var (
+		verificationErr *tls.CertificateVerificationError
+		recordErr       tls.RecordHeaderError
+		alertErr        tls.AlertError
+		hostnameErr     x509.HostnameError
+		authorityErr    x509.UnknownAuthorityError
+		invalidErr      x509.CertificateInvalidError
+	)
+	if errors.As(err, &verificationErr) || errors.As(err, &recordErr) || errors.As(err, &alertErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &authorityErr) || errors.As(err, &invalidErr) {
+		return OutcomeTLSError
+	}
+	return OutcomeNetworkError
+}
+
+// transient reports whether a failed attempt is worth retrying: it timed out, was rate limited,
+// hit a server that is temporarily unavailable, or lost its connection. Failures that would
+// only repeat themselves, such as a missing DNS record, a bad certificate, a redirect loop or
+// an address refused by the SSRF guard, are not retried.
+func transient(outcome Outcome, statusCode int, err error) bool {
+	switch outcome {
+	case OutcomeTimeout, OutcomeRateLimited:
+		return true
+	case OutcomeServerError:
+		switch statusCode {
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	case OutcomeNetworkError:
+		var redirectErr *RedirectError
+		var blockedErr *netguard.BlockedError
+		return !errors.As(err, &redirectErr) && !errors.As(err, &blockedErr)
+	}
+	return false
+}
+
+// retryBackoff returns the wait before the given retry, starting at base and doubling each time.
+func retryBackoff(base time.Duration, retry int) time.Duration {
+	delay := base
+	for i := 0; i < retry && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
@@ -0,0 +1,132 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gayansanjeewa/gogeturl/internal/netguard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyOutcome(t *testing.T) {
+	urlError := func(err error) error {
+		return &url.Error{Op: "Head", URL: "https://example.com", Err: err}
+	}
+
+	tests := []struct {
+		name       string
+		statusCode int
+		redirected bool
+		err        error
+		expected   Outcome
+		broken     bool
+	}{
+		{"ok", 200, false, nil, OutcomeOK, false},
+		{"redirected", 200, true, nil, OutcomeRedirect, false},
+		{"not found", 404, false, nil, OutcomeClientError, true},
+		{"not found after redirect", 404, true, nil, OutcomeClientError, true},
+		{"rate limited", 429, false, nil, OutcomeRateLimited, false},
+		{"server error", 503, false, nil, OutcomeServerError, true},
+		{"deadline", 0, false, urlError(context.DeadlineExceeded), OutcomeTimeout, false},
+		{"DNS not found", 0, false, urlError(&net.DNSError{Err: "no such host", IsNotFound: true}), OutcomeDNSFailure, true},
+		{"DNS timeout", 0, false, urlError(&net.DNSError{Err: "i/o timeout", IsTimeout: true}), OutcomeTimeout, false},
+		{"untrusted certificate", 0, false, urlError(x509.UnknownAuthorityError{}), OutcomeTLSError, true},
+		{"connection refused", 0, false, urlError(errors.New("connection refused")), OutcomeNetworkError, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			outcome := classifyOutcome(testCase.statusCode, testCase.redirected, testCase.err)
+			assert.Equal(t, testCase.expected, outcome)
+			assert.Equal(t, testCase.broken, outcome.Broken())
+		})
+	}
+}
+
+func TestTransient(t *testing.T) {
+	assert.True(t, transient(OutcomeTimeout, 0, context.DeadlineExceeded))
+	assert.True(t, transient(OutcomeRateLimited, 429, nil))
+	assert.True(t, transient(OutcomeServerError, 503, nil))
+	assert.False(t, transient(OutcomeServerError, 501, nil))
+	assert.True(t, transient(OutcomeNetworkError, 0, errors.New("connection reset by peer")))
+	assert.False(t, transient(OutcomeNetworkError, 0, &RedirectError{Err: ErrRedirectLoop}))
+	assert.False(t, transient(OutcomeNetworkError, 0, fmt.Errorf("dial: %w", &netguard.BlockedError{Address: "10.0.0.1:80"})))
+	assert.False(t, transient(OutcomeClientError, 404, nil))
+	assert.False(t, transient(OutcomeDNSFailure, 0, &net.DNSError{IsNotFound: true}))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, 500*time.Millisecond, retryBackoff(500*time.Millisecond, 0))
+	assert.Equal(t, time.Second, retryBackoff(500*time.Millisecond, 1))
+	assert.Equal(t, 2*time.Second, retryBackoff(500*time.Millisecond, 2))
+	assert.Equal(t, maxRetryBackoff, retryBackoff(500*time.Millisecond, 10))
+}
+
+func TestCheckLinks_RetriesTransientFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		respond  func(attempt int32) (*http.Response, error)
+		attempts int
+		outcome  Outcome
+	}{
+		{
+			name: "recovers after server errors",
+			respond: func(attempt int32) (*http.Response, error) {
+				if attempt < 3 {
+					return &http.Response{StatusCode: 503, Body: io.NopCloser(strings.NewReader(""))}, nil
+				}
+				return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("OK"))}, nil
+			},
+			attempts: 3,
+			outcome:  OutcomeOK,
+		},
+		{
+			name: "keeps timing out",
+			respond: func(int32) (*http.Response, error) {
+				return nil, context.DeadlineExceeded
+			},
+			attempts: 3,
+			outcome:  OutcomeTimeout,
+		},
+		{
+			name: "not found is final",
+			respond: func(int32) (*http.Response, error) {
+				return &http.Response{StatusCode: 404, Body: io.NopCloser(strings.NewReader(""))}, nil
+			},
+			attempts: 1,
+			outcome:  OutcomeClientError,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var requests int32
+			client := &mockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					return testCase.respond(atomic.AddInt32(&requests, 1))
+				},
+			}
+
+			analyser := NewAnalyzer(client).(*DefaultAnalyzer)
+			analyser.RetryBackoff = time.Millisecond
+			results, err := analyser.checkLinks(context.Background(), []extractedLink{{href: "/page", link: "/page", tag: "a"}}, "https://example.com")
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.attempts, results[0].Attempts)
+			assert.Equal(t, int32(testCase.attempts), atomic.LoadInt32(&requests))
+			assert.Equal(t, testCase.outcome, results[0].Outcome)
+			assert.Equal(t, testCase.outcome.Broken(), results[0].Broken)
+		})
+	}
+}
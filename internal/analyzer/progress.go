@@ -0,0 +1,48 @@
+package analyzer
+
+import "context"
+
+// EventType identifies a progress event emitted while an analysis runs.
+type EventType string
+
+const (
+	EventFetched     EventType = "fetched"
+	EventLinkChecked EventType = "link_checked"
+	EventBrokenLink  EventType = "broken_link"
+	EventDone        EventType = "done"
+)
+
+// ProgressEvent describes how far an analysis has come.
+// Checked and Total count links; Total is known once the page has been fetched and parsed.
+// Link is set on link_checked and broken_link events, and Summary on the final done event.
+type ProgressEvent struct {
+	Type    EventType    `json:"type"`
+	Checked int          `json:"checked"`
+	Total   int          `json:"total"`
+	Link    *LinkResult  `json:"link,omitempty"`
+	Summary *LinkSummary `json:"summary,omitempty"`
+}
+
+// ProgressFunc receives progress events. Calls for a single analysis are never made concurrently.
+type ProgressFunc func(event ProgressEvent)
+
+type progressKey struct{}
+
+// WithProgress returns a context that makes Analyze report its progress to fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressFrom returns the ProgressFunc attached to ctx, or a no-op if there is none.
+func progressFrom(ctx context.Context) ProgressFunc {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && fn != nil {
+		return fn
+	}
+	return func(ProgressEvent) {}
+}
+
+// ReportProgress sends event to the ProgressFunc attached to ctx, if any.
+// It lets other Analyzer implementations, such as test doubles, report progress the same way.
+func ReportProgress(ctx context.Context, event ProgressEvent) {
+	progressFrom(ctx)(event)
+}
@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// maxRedirects matches the limit of the net/http default redirect policy.
+	maxRedirects = 10
+	// drainLimit caps how much of an unwanted body is read so its connection can be reused.
+	drainLimit = 4 << 10
+)
+
+var (
+	// ErrRedirectLoop means a redirect pointed back to a URL already visited in the same chain.
+	ErrRedirectLoop = errors.New("redirect loop detected")
+	// ErrTooManyRedirects means the chain was longer than maxRedirects hops.
+	ErrTooManyRedirects = errors.New("too many redirects")
+)
+
+// RedirectError is returned when a redirect chain cannot be followed to the end.
+// It wraps ErrRedirectLoop or ErrTooManyRedirects and keeps the hops followed so far.
+type RedirectError struct {
+	Err  error
+	Hops []RedirectHop
+}
+
+func (e *RedirectError) Error() string {
+	if len(e.Hops) == 0 {
+		return e.Err.Error()
+	}
+	return e.Err.Error() + " at " + e.Hops[len(e.Hops)-1].Location
+}
+
+func (e *RedirectError) Unwrap() error {
+	return e.Err
+}
+
+// stopRedirects makes an http.Client return redirect responses instead of following them.
+func stopRedirects(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// withoutRedirects returns a copy of client that leaves redirects to the analyzer, so every hop can be recorded.
+// Clients other than *http.Client are returned unchanged.
+func withoutRedirects(client HTTPClient) HTTPClient {
+	httpClient, ok := client.(*http.Client)
+	if !ok {
+		return client
+	}
+	copied := *httpClient
+	copied.CheckRedirect = stopRedirects
+	return &copied
+}
+
+// follow sends a request and follows redirects itself, recording the status, Location and timing of each hop.
+// It returns the first response that is not a redirect, whose body the caller must close.
+// HTTPS to HTTP hops are flagged as downgrades; loops and overly long chains fail with a RedirectError.
+// A non-nil timer traces every hop, ending up with the phases of the final request.
+func (analyser *DefaultAnalyzer) follow(ctx context.Context, method, target string, header http.Header, timer *requestTimer) (*http.Response, []RedirectHop, error) {
+	var hops []RedirectHop
+	visited := map[string]bool{}
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, method, target, nil)
+		if err != nil {
+			return nil, hops, err
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		if analyser.UserAgent != "" {
+			req.Header.Set("User-Agent", analyser.UserAgent)
+		}
+		visited[req.URL.String()] = true
+		if timer != nil {
+			req = timer.attach(req)
+		}
+
+		start := time.Now()
+		resp, err := analyser.Client.Do(req)
+		if err != nil {
+			return nil, hops, err
+		}
+
+		if resp.Request == nil {
+			resp.Request = req
+		}
+
+		location := resp.Header.Get("Location")
+		if !isRedirect(resp.StatusCode) || location == "" {
+			return resp, hops, nil
+		}
+		closeBody(resp)
+
+		next, err := req.URL.Parse(location)
+		if err != nil {
+			return nil, hops, err
+		}
+
+		hops = append(hops, RedirectHop{
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Location:   next.String(),
+			Latency:    time.Since(start),
+			Downgrade:  req.URL.Scheme == "https" && next.Scheme == "http",
+		})
+
+		switch {
+		case visited[next.String()]:
+			return nil, hops, &RedirectError{Err: ErrRedirectLoop, Hops: hops}
+		case len(hops) >= maxRedirects:
+			return nil, hops, &RedirectError{Err: ErrTooManyRedirects, Hops: hops}
+		}
+		target = next.String()
+	}
+}
+
+// finalURL returns the URL a response was served from, after any redirects.
+func finalURL(resp *http.Response) string {
+	return resp.Request.URL.String()
+}
+
+// closeBody discards a short unread body before closing it, which lets the transport reuse the connection.
+func closeBody(resp *http.Response) {
+	if resp.Body != nil {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, drainLimit))
+		_ = resp.Body.Close()
+	}
+}
+
+func isRedirect(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// downgraded reports whether any hop of a redirect chain moved from HTTPS to HTTP.
+func downgraded(hops []RedirectHop) bool {
+	for _, hop := range hops {
+		if hop.Downgrade {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// redirectingClient serves the given routes, keyed by full URL. A route starting with "->" redirects
+// to the rest of the string with a 301; any other route is served as an HTML page. Unknown URLs return 404.
+func redirectingClient(routes map[string]string) *mockHTTPClient {
+	return &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			route, ok := routes[req.URL.String()]
+			header := http.Header{}
+			switch {
+			case !ok:
+				return &http.Response{StatusCode: 404, Header: header, Body: io.NopCloser(strings.NewReader(""))}, nil
+			case strings.HasPrefix(route, "->"):
+				header.Set("Location", strings.TrimPrefix(route, "->"))
+				return &http.Response{StatusCode: 301, Header: header, Body: io.NopCloser(strings.NewReader(""))}, nil
+			default:
+				header.Set("Content-Type", "text/html")
+				return &http.Response{StatusCode: 200, Header: header, Body: io.NopCloser(strings.NewReader(route))}, nil
+			}
+		},
+	}
+}
+
+func TestAnalyze_RedirectChain(t *testing.T) {
+	client := redirectingClient(map[string]string{
+		"http://old.example.com":         "->https://old.example.com/",
+		"https://old.example.com/":       "->https://new.example.com/home",
+		"https://new.example.com/home":   `<a href="/about">About</a><a href="https://old.example.com/legacy">Legacy</a>`,
+		"https://new.example.com/about":  "about",
+		"https://old.example.com/legacy": "->https://new.example.com/about",
+	})
+
+	report, err := NewAnalyzer(client).Analyze(context.Background(), "http://old.example.com")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://new.example.com/home", report.FinalURL)
+	assert.False(t, report.Redirects.Downgrade)
+	assert.False(t, report.Redirects.Loop)
+	if assert.Len(t, report.Redirects.Hops, 2) {
+		assert.Equal(t, "http://old.example.com", report.Redirects.Hops[0].URL)
+		assert.Equal(t, 301, report.Redirects.Hops[0].StatusCode)
+		assert.Equal(t, "https://old.example.com/", report.Redirects.Hops[0].Location)
+		assert.Equal(t, "https://new.example.com/home", report.Redirects.Hops[1].Location)
+	}
+
+	// Links are classified against the final host, not the one originally requested
+	assert.Equal(t, LinkSummary{Internal: 1, External: 1, Broken: 0, Outcomes: map[Outcome]int{OutcomeOK: 1, OutcomeRedirect: 1}}, report.Links)
+	assert.Equal(t, "https://new.example.com/about", report.LinkResults[0].URL)
+	assert.Empty(t, report.LinkResults[0].RedirectedTo)
+	assert.Equal(t, 200, report.LinkResults[1].StatusCode)
+	assert.Equal(t, "https://new.example.com/about", report.LinkResults[1].RedirectedTo)
+}
+
+func TestAnalyze_RedirectLoop(t *testing.T) {
+	client := redirectingClient(map[string]string{
+		"http://a.example.com/":      "->http://b.example.com/",
+		"http://b.example.com/":      "->/again",
+		"http://b.example.com/again": "->http://a.example.com/",
+	})
+
+	report, err := NewAnalyzer(client).Analyze(context.Background(), "http://a.example.com/")
+
+	var redirectErr *RedirectError
+	assert.True(t, errors.As(err, &redirectErr), "expected RedirectError, got %v", err)
+	assert.ErrorIs(t, err, ErrRedirectLoop)
+	assert.True(t, report.Redirects.Loop)
+	assert.Len(t, report.Redirects.Hops, 3)
+	assert.Equal(t, "http://b.example.com/again", report.Redirects.Hops[1].Location)
+	assert.Contains(t, report.Errors[SectionFetch], "redirect loop")
+}
+
+func TestFetchHTML_TooManyRedirects(t *testing.T) {
+	client := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			header.Set("Location", req.URL.Path+"x")
+			return &http.Response{StatusCode: 302, Header: header, Body: io.NopCloser(strings.NewReader(""))}, nil
+		},
+	}
+
+	_, err := NewAnalyzer(client).FetchHTML(context.Background(), "http://example.com/")
+
+	var redirectErr *RedirectError
+	assert.True(t, errors.As(err, &redirectErr), "expected RedirectError, got %v", err)
+	assert.ErrorIs(t, err, ErrTooManyRedirects)
+	assert.Len(t, redirectErr.Hops, maxRedirects)
+}
+
+func TestAnalyze_RedirectDowngrade(t *testing.T) {
+	client := redirectingClient(map[string]string{
+		"https://secure.example.com/": "->http://plain.example.com/",
+		"http://plain.example.com/":   "<title>Plain</title>",
+	})
+
+	report, err := NewAnalyzer(client).Analyze(context.Background(), "https://secure.example.com/")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Plain", report.Title)
+	assert.True(t, report.Redirects.Downgrade)
+	assert.True(t, report.Redirects.Hops[0].Downgrade)
+}
+
+func TestNewAnalyzer_RecordsRedirectsOfHTTPClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/start", http.RedirectHandler("/final", http.StatusFound))
+	mux.HandleFunc("/final", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/html")
+		_, _ = writer.Write([]byte("<title>Final</title>"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := &http.Client{}
+	page, err := NewAnalyzer(client).FetchHTML(context.Background(), server.URL+"/start")
+
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/final", page.URL)
+	if assert.Len(t, page.Redirects, 1) {
+		assert.Equal(t, http.StatusFound, page.Redirects[0].StatusCode)
+		assert.Equal(t, server.URL+"/final", page.Redirects[0].Location)
+	}
+	assert.Nil(t, client.CheckRedirect, "the caller's client must not be modified")
+}
@@ -0,0 +1,133 @@
+package analyzer
+
+import (
+	"errors"
+	"time"
+)
+
+// Section names used as keys in AnalysisReport.Errors.
+const (
+	SectionFetch = "fetch"
+	SectionLinks = "links"
+)
+
+// AnalysisReport is the structured result of analyzing a single page.
+// It is safe to serialize as JSON and is what both the web UI and API consumers receive.
+type AnalysisReport struct {
+	URL string `json:"url"`
+	// FinalURL is the URL the page was served from after following redirects; links are classified against it.
+	FinalURL     string             `json:"final_url"`
+	Redirects    RedirectChain      `json:"redirects"`
+	Response     ResponseInfo       `json:"response"`
+	TLS          *TLSInfo           `json:"tls,omitempty"`
+	Page         PageInfo           `json:"page"`
+	HTMLVersion  string             `json:"html_version"`
+	Title        string             `json:"title"`
+	Headings     map[string]int     `json:"headings"`
+	Links        LinkSummary        `json:"links"`
+	LinkResults  []LinkResult       `json:"link_results"`
+	LinkCache    CacheStats         `json:"link_cache"`
+	Resources    ResourceReport     `json:"resources"`
+	HasLoginForm bool               `json:"has_login_form"`
+	Security     SecurityReport     `json:"security"`
+	MixedContent MixedContentReport `json:"mixed_content"`
+	SEO          SEOReport          `json:"seo"`
+	Robots       *RobotsReport      `json:"robots,omitempty"`
+	Errors       map[string]string  `json:"errors,omitempty"`
+}
+
+// PageInfo describes the fetched document itself.
+type PageInfo struct {
+	ContentType string `json:"content_type"`
+	Encoding    string `json:"encoding"`
+	Size        int    `json:"size"`
+	// Truncated means the page was larger than the size limit and only its beginning was analyzed.
+	Truncated bool `json:"truncated"`
+}
+
+// RedirectChain describes the redirects followed to reach the page.
+type RedirectChain struct {
+	Hops []RedirectHop `json:"hops"`
+	// Downgrade is set when any hop redirected from HTTPS to plain HTTP.
+	Downgrade bool `json:"https_downgrade"`
+	// Loop is set when the chain pointed back to a URL it had already visited.
+	Loop bool `json:"loop"`
+}
+
+// RedirectHop is a single redirect response: the URL requested, its status code and where it pointed.
+type RedirectHop struct {
+	URL        string        `json:"url"`
+	StatusCode int           `json:"status_code"`
+	Location   string        `json:"location"`
+	Latency    time.Duration `json:"latency_ns"`
+	Downgrade  bool          `json:"https_downgrade,omitempty"`
+}
+
+// LinkSummary holds the counts of internal, external, and broken links on the page,
+// and of the links checked with each outcome.
+type LinkSummary struct {
+	Internal int             `json:"internal"`
+	External int             `json:"external"`
+	Broken   int             `json:"broken"`
+	Outcomes map[Outcome]int `json:"outcomes,omitempty"`
+}
+
+// LinkResult is the outcome of checking a single link found on the page.
+type LinkResult struct {
+	Href string `json:"href"`
+	URL  string `json:"url"`
+	Tag  string `json:"tag"`
+	// Type is set for subresources from the resource inventory.
+	Type     ResourceType `json:"type,omitempty"`
+	Text     string       `json:"text,omitempty"`
+	Internal bool         `json:"internal"`
+	// Outcome classifies the check; it is empty for subresources that were only listed.
+	Outcome Outcome `json:"outcome,omitempty"`
+	// Broken is set for the outcomes that mean the link does not work, see Outcome.Broken.
+	Broken     bool `json:"broken"`
+	StatusCode int  `json:"status_code,omitempty"`
+	// Attempts is the number of times the link was requested, including retries.
+	Attempts int `json:"attempts,omitempty"`
+	// Cached is set when the result was reused from an earlier check instead of requested again.
+	Cached bool `json:"cached,omitempty"`
+	// RedirectedTo is the URL the link finally resolved to, when it redirected.
+	RedirectedTo string `json:"redirected_to,omitempty"`
+	Error        string `json:"error,omitempty"`
+	// Latency is the duration of the last attempt, not counting the waits before retries.
+	Latency time.Duration `json:"latency_ns"`
+}
+
+// summarizeLinks counts internal, external, and broken links from individual link results.
+func summarizeLinks(results []LinkResult) LinkSummary {
+	var summary LinkSummary
+	for _, result := range results {
+		if result.Internal {
+			summary.Internal++
+		} else {
+			summary.External++
+		}
+		if result.Broken {
+			summary.Broken++
+		}
+		if result.Outcome != "" {
+			if summary.Outcomes == nil {
+				summary.Outcomes = make(map[Outcome]int)
+			}
+			summary.Outcomes[result.Outcome]++
+		}
+	}
+	return summary
+}
+
+// newRedirectChain summarizes the hops of a redirect chain; err is the error that ended it, if any.
+func newRedirectChain(hops []RedirectHop, err error) RedirectChain {
+	return RedirectChain{Hops: hops, Downgrade: downgraded(hops), Loop: errors.Is(err, ErrRedirectLoop)}
+}
+
+// addError records a failure for the given report section without aborting the rest of the analysis.
+func (report *AnalysisReport) addError(section string, err error) {
+	if report.Errors == nil {
+		report.Errors = make(map[string]string)
+	}
+	report.Errors[section] = err.Error()
+}
@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"path"
+	"strings"
+)
+
+// ResourceType groups the subresources of a page in the resource inventory.
+type ResourceType string
+
+const (
+	ResourceScript     ResourceType = "script"
+	ResourceStylesheet ResourceType = "stylesheet"
+	ResourceImage      ResourceType = "image"
+	ResourceMedia      ResourceType = "media"
+	ResourceFont       ResourceType = "font"
+	ResourceFrame      ResourceType = "frame"
+	ResourceObject     ResourceType = "object"
+	// ResourceForm is a form target. It is reported as mixed content but never fetched,
+	// since forms are usually submitted with POST.
+	ResourceForm  ResourceType = "form"
+	ResourceOther ResourceType = "other"
+)
+
+// ResourceReport is the inventory of subresources the page loads, with totals per type.
+type ResourceReport struct {
+	// Checked is false when the analyzer was configured not to request subresources.
+	Checked bool                           `json:"checked"`
+	Counts  map[ResourceType]ResourceCount `json:"counts"`
+	Results []LinkResult                   `json:"results"`
+}
+
+// ResourceCount holds the number of resources of one type and how many of them are broken.
+type ResourceCount struct {
+	Total  int `json:"total"`
+	Broken int `json:"broken"`
+}
+
+// fetchable reports whether a resource of this type belongs in the inventory and can be checked.
+func (kind ResourceType) fetchable() bool {
+	return kind != ResourceForm
+}
+
+// passive reports whether mixed content of this type is only displayed, rather than able to change the page.
+func (kind ResourceType) passive() bool {
+	return kind == ResourceImage || kind == ResourceMedia
+}
+
+// cssFontExtensions and cssImageExtensions classify url() references in CSS by file extension.
+var (
+	cssFontExtensions  = map[string]bool{".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true}
+	cssImageExtensions = map[string]bool{
+		".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true,
+		".webp": true, ".avif": true, ".ico": true, ".bmp": true,
+	}
+)
+
+// cssResourceType guesses the type of a url() reference from its extension.
+func cssResourceType(reference string) ResourceType {
+	if index := strings.IndexAny(reference, "?#"); index >= 0 {
+		reference = reference[:index]
+	}
+	extension := strings.ToLower(path.Ext(reference))
+	switch {
+	case cssFontExtensions[extension]:
+		return ResourceFont
+	case cssImageExtensions[extension]:
+		return ResourceImage
+	case extension == ".css":
+		return ResourceStylesheet
+	default:
+		return ResourceOther
+	}
+}
+
+// inventoryLinks converts the fetchable resources into links for the checking worker pool.
+func inventoryLinks(resources []extractedResource) []extractedLink {
+	var links []extractedLink
+	for _, resource := range resources {
+		if resource.kind.fetchable() {
+			links = append(links, extractedLink{href: resource.href, link: resource.url, tag: resource.tag, kind: resource.kind})
+		}
+	}
+	return links
+}
+
+// summarizeResources counts the resources of each type, and the broken ones if they were checked.
+func summarizeResources(results []LinkResult, checked bool) ResourceReport {
+	report := ResourceReport{Checked: checked, Counts: make(map[ResourceType]ResourceCount), Results: results}
+	for _, result := range results {
+		count := report.Counts[result.Type]
+		count.Total++
+		if result.Broken {
+			count.Broken++
+		}
+		report.Counts[result.Type] = count
+	}
+	return report
+}
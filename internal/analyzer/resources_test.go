@@ -0,0 +1,177 @@
+package analyzer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const inventoryPage = `<!DOCTYPE html>
+<html>
+<head>
+	<link rel="stylesheet" href="/site.css">
+	<link rel="icon" href="/favicon.ico">
+	<link rel="manifest" href="/app.webmanifest">
+	<link rel="canonical" href="/page">
+	<script src="/app.js"></script>
+	<style>
+		@import "print.css";
+		@font-face { src: url('/fonts/body.woff2') format('woff2'); }
+		.hero { background: url(/img/hero.jpg); }
+		.logo { background: url("data:image/png;base64,AAAA"); }
+	</style>
+</head>
+<body>
+	<a href="/about">About</a>
+	<img src="/img/a.png" srcset="/img/a-1x.png 1x, /img/a-2x.png 2x">
+	<img src="data:image/gif;base64,R0lGOD" alt="inline">
+	<picture><source srcset="/img/b.webp 480w, /img/b-large.webp 960w" type="image/webp"></picture>
+	<video src="/media/clip.mp4" poster="/img/poster.jpg"><track src="/media/captions.vtt"></video>
+	<audio><source src="/media/song.mp3"></audio>
+	<iframe src="https://widgets.example.net/embed"></iframe>
+	<div style="background-image: url('/img/missing.png')"></div>
+	<form action="/login"></form>
+	<a href="javascript:void(0)">Nothing</a>
+</body>
+</html>`
+
+func TestResourceCollector(t *testing.T) {
+	resources := &resourceCollector{}
+	walkDocument(inventoryPage, resources)
+
+	type found struct {
+		tag, attribute, url string
+		kind                ResourceType
+	}
+	var actual []found
+	for _, resource := range resources.resources {
+		actual = append(actual, found{resource.tag, resource.attribute, resource.url, resource.kind})
+	}
+
+	assert.Equal(t, []found{
+		{"link", "href", "/site.css", ResourceStylesheet},
+		{"link", "href", "/favicon.ico", ResourceImage},
+		{"link", "href", "/app.webmanifest", ResourceOther},
+		{"script", "src", "/app.js", ResourceScript},
+		{"style", "", "print.css", ResourceStylesheet},
+		{"style", "", "/fonts/body.woff2", ResourceFont},
+		{"style", "", "/img/hero.jpg", ResourceImage},
+		{"img", "src", "/img/a.png", ResourceImage},
+		{"img", "srcset", "/img/a-1x.png", ResourceImage},
+		{"img", "srcset", "/img/a-2x.png", ResourceImage},
+		{"source", "srcset", "/img/b.webp", ResourceImage},
+		{"source", "srcset", "/img/b-large.webp", ResourceImage},
+		{"video", "src", "/media/clip.mp4", ResourceMedia},
+		{"video", "poster", "/img/poster.jpg", ResourceImage},
+		{"track", "src", "/media/captions.vtt", ResourceMedia},
+		{"source", "src", "/media/song.mp3", ResourceMedia},
+		{"iframe", "src", "https://widgets.example.net/embed", ResourceFrame},
+		{"div", "style", "/img/missing.png", ResourceImage},
+		{"form", "action", "/login", ResourceForm},
+	}, actual)
+}
+
+func TestParseSrcset(t *testing.T) {
+	tests := []struct {
+		srcset   string
+		expected []string
+	}{
+		{"", nil},
+		{"a.png", []string{"a.png"}},
+		{"a.png 1x, b.png 2x", []string{"a.png", "b.png"}},
+		{"a.png 480w,b.png 960w", []string{"a.png", "b.png"}},
+		{"a.png,b.png", []string{"a.png,b.png"}},
+		{"a.png, b.png", []string{"a.png", "b.png"}},
+		{"data:image/png;base64,AAAA 1x, b.png 2x", []string{"data:image/png;base64,AAAA", "b.png"}},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.srcset, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, parseSrcset(testCase.srcset))
+		})
+	}
+}
+
+func TestAnalyze_ResourceInventory(t *testing.T) {
+	var requests atomic.Int32
+	client := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			requests.Add(1)
+			status, body := 200, "OK"
+			switch req.URL.Path {
+			case "/page":
+				body = inventoryPage
+			case "/img/missing.png", "/media/song.mp3":
+				status = 404
+			}
+			return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}
+
+	report, err := NewAnalyzer(client).Analyze(context.Background(), "https://example.com/page")
+
+	assert.NoError(t, err)
+	// The page and its robots.txt, then every distinct URL among the links and the 18 fetchable resources
+	assert.Equal(t, CacheStats{Misses: len(report.LinkResults) + 18 - 3, Duplicates: 3}, report.LinkCache)
+	assert.Equal(t, int32(2+report.LinkCache.Misses), requests.Load())
+	assert.True(t, report.Resources.Checked)
+	assert.Len(t, report.Resources.Results, 18)
+	assert.Equal(t, map[ResourceType]ResourceCount{
+		ResourceStylesheet: {Total: 2},
+		ResourceImage:      {Total: 9, Broken: 1},
+		ResourceFont:       {Total: 1},
+		ResourceScript:     {Total: 1},
+		ResourceMedia:      {Total: 3, Broken: 1},
+		ResourceFrame:      {Total: 1},
+		ResourceOther:      {Total: 1},
+	}, report.Resources.Counts)
+
+	frame := report.Resources.Results[16]
+	assert.Equal(t, ResourceFrame, frame.Type)
+	assert.False(t, frame.Internal)
+	assert.Equal(t, "https://example.com/img/missing.png", report.Resources.Results[17].URL)
+	assert.Equal(t, 404, report.Resources.Results[17].StatusCode)
+
+	// Resources are kept out of the link summary
+	for _, link := range report.LinkResults {
+		assert.Empty(t, link.Type)
+	}
+}
+
+func TestAnalyze_ResourceInventoryUnchecked(t *testing.T) {
+	var requests atomic.Int32
+	client := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			requests.Add(1)
+			body := "OK"
+			if req.URL.Path == "/page" {
+				body = inventoryPage
+			}
+			return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}
+	analyser := NewAnalyzer(client).(*DefaultAnalyzer)
+	analyser.CheckResources = false
+
+	var done ProgressEvent
+	ctx := WithProgress(context.Background(), func(event ProgressEvent) {
+		if event.Type == EventDone {
+			done = event
+		}
+	})
+	report, err := analyser.Analyze(ctx, "https://example.com/page")
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(report.LinkResults), done.Total)
+	assert.Equal(t, done.Total, done.Checked)
+	assert.Equal(t, int32(2+len(report.LinkResults)), requests.Load())
+	assert.False(t, report.Resources.Checked)
+	assert.Len(t, report.Resources.Results, 18)
+	assert.Equal(t, "https://example.com/site.css", report.Resources.Results[0].URL)
+	assert.Zero(t, report.Resources.Results[0].StatusCode)
+}
@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// ResponseInfo describes the HTTP response the page was served with, after any redirects.
+type ResponseInfo struct {
+	StatusCode int    `json:"status_code"`
+	Protocol   string `json:"protocol"`
+	Server     string `json:"server,omitempty"`
+	// ContentLength is the length declared by the server, or -1 when it was not declared.
+	ContentLength int64 `json:"content_length"`
+	// ContentEncoding is the compression the body was transferred with, such as "gzip", even when
+	// the HTTP client decompressed it transparently.
+	ContentEncoding string         `json:"content_encoding,omitempty"`
+	Headers         http.Header    `json:"headers"`
+	Timing          ResponseTiming `json:"timing"`
+}
+
+// ResponseTiming breaks down how long each phase of the final request took.
+// Phases that did not happen, such as DNS and connect on a reused connection, are zero.
+type ResponseTiming struct {
+	DNS     time.Duration `json:"dns_ns"`
+	Connect time.Duration `json:"connect_ns"`
+	TLS     time.Duration `json:"tls_ns"`
+	// TTFB is the time from sending the request to receiving the first byte of the response.
+	TTFB     time.Duration `json:"ttfb_ns"`
+	Download time.Duration `json:"download_ns"`
+	Total    time.Duration `json:"total_ns"`
+}
+
+// newResponseInfo captures the metadata of resp. Timing is filled in separately by a requestTimer.
+func newResponseInfo(resp *http.Response) ResponseInfo {
+	info := ResponseInfo{
+		StatusCode:      resp.StatusCode,
+		Protocol:        resp.Proto,
+		Server:          resp.Header.Get("Server"),
+		ContentLength:   resp.ContentLength,
+		ContentEncoding: resp.Header.Get("Content-Encoding"),
+		Headers:         resp.Header,
+	}
+	// The transport strips Content-Encoding when it decompresses a gzip response it asked for itself
+	if resp.Uncompressed && info.ContentEncoding == "" {
+		info.ContentEncoding = "gzip"
+	}
+	return info
+}
+
+// requestTimer records the phases of a request through httptrace. It is reset for every hop
+// of a redirect chain, so once the body has been read it holds the timings of the final response.
+type requestTimer struct {
+	mutex  sync.Mutex
+	phases requestPhases
+}
+
+// requestPhases holds the moments each phase of a request started and ended.
+type requestPhases struct {
+	start                     time.Time
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	wroteRequest, firstByte   time.Time
+	bodyRead                  time.Time
+}
+
+// attach resets the timer and returns req with a trace that feeds it.
+// Trace hooks may run concurrently, for example while racing IPv4 and IPv6 connections.
+func (timer *requestTimer) attach(req *http.Request) *http.Request {
+	timer.mutex.Lock()
+	timer.phases = requestPhases{start: time.Now()}
+	timer.mutex.Unlock()
+
+	record := func(field *time.Time) {
+		timer.mutex.Lock()
+		defer timer.mutex.Unlock()
+		if field.IsZero() {
+			*field = time.Now()
+		}
+	}
+
+	phases := &timer.phases
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { record(&phases.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { record(&phases.dnsDone) },
+		ConnectStart:         func(string, string) { record(&phases.connectStart) },
+		ConnectDone:          func(string, string, error) { record(&phases.connectDone) },
+		TLSHandshakeStart:    func() { record(&phases.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { record(&phases.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { record(&phases.wroteRequest) },
+		GotFirstResponseByte: func() { record(&phases.firstByte) },
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// finish marks the end of reading the response body.
+func (timer *requestTimer) finish() {
+	timer.mutex.Lock()
+	defer timer.mutex.Unlock()
+	timer.phases.bodyRead = time.Now()
+}
+
+// timing returns the recorded phases.
+func (timer *requestTimer) timing() ResponseTiming {
+	timer.mutex.Lock()
+	defer timer.mutex.Unlock()
+
+	phases := timer.phases
+	return ResponseTiming{
+		DNS:      between(phases.dnsStart, phases.dnsDone),
+		Connect:  between(phases.connectStart, phases.connectDone),
+		TLS:      between(phases.tlsStart, phases.tlsDone),
+		TTFB:     between(phases.wroteRequest, phases.firstByte),
+		Download: between(phases.firstByte, phases.bodyRead),
+		Total:    between(phases.start, phases.bodyRead),
+	}
+}
+
+// between returns the time from start to end, or zero if either was never recorded.
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
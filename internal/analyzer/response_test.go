@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchHTML_ResponseMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		writer.Header().Set("Server", "test-server/1.0")
+		writer.Header().Set("Content-Type", "text/html")
+		writer.Header().Set("Content-Encoding", "gzip")
+		compressed := gzip.NewWriter(writer)
+		_, _ = compressed.Write([]byte("<title>Compressed</title>"))
+		_ = compressed.Close()
+	}))
+	defer server.Close()
+
+	page, err := NewAnalyzer(&http.Client{}).FetchHTML(context.Background(), server.URL)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "<title>Compressed</title>", page.Body)
+
+	response := page.Response
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "HTTP/1.1", response.Protocol)
+	assert.Equal(t, "test-server/1.0", response.Server)
+	assert.Equal(t, "gzip", response.ContentEncoding)
+	assert.Equal(t, "text/html", response.Headers.Get("Content-Type"))
+
+	assert.Positive(t, response.Timing.Connect)
+	assert.Zero(t, response.Timing.TLS)
+	assert.Positive(t, response.Timing.TTFB)
+	assert.GreaterOrEqual(t, response.Timing.Total, response.Timing.TTFB+response.Timing.Download)
+}
+
+func TestFetchHTML_ResponseMetadataHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		writer.Header().Set("Content-Type", "text/html")
+		_, _ = writer.Write([]byte("<title>Secure</title>"))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	page, err := NewAnalyzer(server.Client()).FetchHTML(context.Background(), server.URL)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", page.Response.Protocol)
+	assert.Empty(t, page.Response.ContentEncoding)
+	assert.Positive(t, page.Response.Timing.TLS)
+}
+
+func TestFetchHTML_ResponseTimingIsForFinalHop(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/old", http.RedirectHandler("/new", http.StatusMovedPermanently))
+	mux.HandleFunc("/new", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/html")
+		_, _ = writer.Write([]byte("<title>New</title>"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	page, err := NewAnalyzer(&http.Client{}).FetchHTML(context.Background(), server.URL+"/old")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, page.Response.StatusCode)
+	// The second request reuses the connection opened for the redirect
+	assert.Zero(t, page.Response.Timing.Connect)
+	assert.Positive(t, page.Response.Timing.TTFB)
+}
@@ -0,0 +1,232 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gayansanjeewa/gogeturl/internal/robots"
+)
+
+const (
+	// DefaultUserAgent is the User-Agent NewAnalyzer sends and looks itself up by in robots.txt.
+	DefaultUserAgent = "gogeturl/1.0 (+https://github.com/gayansanjeewa/gogeturl)"
+	// DefaultRobotsTTL is how long NewAnalyzer keeps the robots.txt of a site before fetching it again.
+	DefaultRobotsTTL = time.Hour
+	// maxRobotsEntries bounds the number of sites a long-running cache remembers.
+	maxRobotsEntries = 10_000
+	// robotsFetchTimeout bounds the fetch of one robots.txt, redirects included.
+	robotsFetchTimeout = 30 * time.Second
+)
+
+// CommonCrawlers are the crawlers the robots report lists alongside the analyzer itself.
+var CommonCrawlers = []string{"Googlebot", "Bingbot", "DuckDuckBot", "YandexBot", "Baiduspider", "Applebot", "GPTBot", "CCBot"}
+
+// RobotsReport tells whether the robots.txt of the page's site lets well-known crawlers, and the
+// analyzer itself, request the page.
+type RobotsReport struct {
+	// URL is the robots.txt that was consulted.
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code,omitempty"`
+	// Error is set when robots.txt could not be fetched. A server error disallows everything, as
+	// RFC 9309 asks; any other failure allows everything.
+	Error    string          `json:"error,omitempty"`
+	Crawlers []CrawlerAccess `json:"crawlers"`
+}
+
+// CrawlerAccess is whether one crawler may request the page, and how long it is asked to wait between requests.
+type CrawlerAccess struct {
+	UserAgent  string        `json:"user_agent"`
+	Allowed    bool          `json:"allowed"`
+	CrawlDelay time.Duration `json:"crawl_delay_ns,omitempty"`
+}
+
+// RobotsCache keeps the robots.txt of each site for a fixed time. Lookups of a site whose robots.txt
+// is being fetched wait for that fetch instead of starting another. It is safe for concurrent use
+// and is meant to be shared by every analysis. A nil *RobotsCache never fetches robots.txt.
+type RobotsCache struct {
+	ttl   time.Duration
+	mutex sync.Mutex
+	sites map[string]*robotsFile
+}
+
+// robotsFile is the robots.txt of one site. rules is nil when the site has none, which allows everything,
+// or when it answered with a server error, which disallows everything.
+type robotsFile struct {
+	url        string
+	statusCode int
+	err        error
+	rules      *robots.Rules
+	fetchedAt  time.Time
+	// ready is closed once the file has been fetched
+	ready chan struct{}
+}
+
+// NewRobotsCache creates a RobotsCache that keeps each robots.txt for ttl.
+func NewRobotsCache(ttl time.Duration) *RobotsCache {
+	return &RobotsCache{ttl: ttl, sites: make(map[string]*robotsFile)}
+}
+
+// file returns the robots.txt of the site at origin, calling fetch if it is not cached.
+// The fetch belongs to no single caller: it runs on a context detached from ctx, bounded by
+// robotsFetchTimeout, so a caller that gives up does not fail the others waiting for it.
+// It returns nil if ctx is done before the file is available.
+func (cache *RobotsCache) file(ctx context.Context, origin string, fetch func(ctx context.Context) *robotsFile) *robotsFile {
+	cache.mutex.Lock()
+	file, ok := cache.sites[origin]
+	if !ok || (isClosed(file.ready) && time.Since(file.fetchedAt) >= cache.ttl) {
+		if len(cache.sites) >= maxRobotsEntries {
+			cache.forgetExpired()
+		}
+		file = &robotsFile{ready: make(chan struct{})}
+		cache.sites[origin] = file
+		go cache.fetch(context.WithoutCancel(ctx), origin, file, fetch)
+	}
+	cache.mutex.Unlock()
+
+	select {
+	case <-file.ready:
+		return file
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// fetch fills in file from fetch and wakes up the callers waiting for it.
+func (cache *RobotsCache) fetch(ctx context.Context, origin string, file *robotsFile, fetch func(ctx context.Context) *robotsFile) {
+	ctx, cancel := context.WithTimeout(ctx, robotsFetchTimeout)
+	defer cancel()
+	fetched := fetch(ctx)
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	file.url, file.statusCode, file.err, file.rules, file.fetchedAt = fetched.url, fetched.statusCode, fetched.err, fetched.rules, time.Now()
+	// A fetch that ran out of time says little about the site, so the next lookup tries again
+	if errors.Is(fetched.err, context.DeadlineExceeded) {
+		delete(cache.sites, origin)
+	}
+	close(file.ready)
+}
+
+// forgetExpired drops the files that are due to be fetched again. The caller must hold the mutex.
+func (cache *RobotsCache) forgetExpired() {
+	for origin, file := range cache.sites {
+		if isClosed(file.ready) && time.Since(file.fetchedAt) >= cache.ttl {
+			delete(cache.sites, origin)
+		}
+	}
+}
+
+func isClosed(ready chan struct{}) bool {
+	select {
+	case <-ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// robots returns the robots.txt of the site of rawURL, or nil if analyser.Robots is nil or ctx is done first.
+func (analyser *DefaultAnalyzer) robots(ctx context.Context, rawURL string) *robotsFile {
+	if analyser.Robots == nil {
+		return nil
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return nil
+	}
+	origin := parsed.Scheme + "://" + parsed.Host
+	return analyser.Robots.file(ctx, origin, func(ctx context.Context) *robotsFile {
+		return analyser.fetchRobots(ctx, origin+"/robots.txt")
+	})
+}
+
+// fetchRobots requests a robots.txt, following redirects. A file that is missing or cannot be reached
+// allows everything: a site that is down is then reported by its link checks rather than as blocked.
+// A server error means the site is up but its rules are unknown, so, as RFC 9309 asks, nothing is allowed.
+func (analyser *DefaultAnalyzer) fetchRobots(ctx context.Context, robotsURL string) *robotsFile {
+	file := &robotsFile{url: robotsURL}
+	resp, _, err := analyser.follow(ctx, http.MethodGet, robotsURL, nil, nil)
+	if err != nil {
+		file.err = err
+		return file
+	}
+	defer closeBody(resp)
+
+	file.statusCode = resp.StatusCode
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		file.rules = robots.Parse(resp.Body)
+	case resp.StatusCode >= 500:
+		file.err = fmt.Errorf("robots.txt responded with %s", resp.Status)
+	}
+	return file
+}
+
+// allows reports whether the file lets userAgent request rawURL. A nil file allows everything.
+func (file *robotsFile) allows(userAgent, rawURL string) bool {
+	if file == nil {
+		return true
+	}
+	if file.statusCode >= 500 {
+		return false
+	}
+	return file.rules.Allowed(userAgent, rawURL)
+}
+
+// crawlDelay returns the Crawl-delay the file asks userAgent to keep to.
+func (file *robotsFile) crawlDelay(userAgent string) time.Duration {
+	if file == nil {
+		return 0
+	}
+	return file.rules.CrawlDelay(userAgent)
+}
+
+// sitemaps returns the sitemaps the file lists.
+func (file *robotsFile) sitemaps() []string {
+	if file == nil {
+		return nil
+	}
+	return file.rules.Sitemaps()
+}
+
+// checkRobots reports whether the analyzer and CommonCrawlers may request pageURL.
+func (analyser *DefaultAnalyzer) checkRobots(ctx context.Context, pageURL string) *RobotsReport {
+	file := analyser.robots(ctx, pageURL)
+	if file == nil {
+		return nil
+	}
+
+	report := &RobotsReport{URL: file.url, StatusCode: file.statusCode}
+	if file.err != nil {
+		report.Error = file.err.Error()
+	}
+	userAgents := CommonCrawlers
+	if analyser.UserAgent != "" {
+		userAgents = append([]string{analyser.UserAgent}, CommonCrawlers...)
+	}
+	for _, userAgent := range userAgents {
+		report.Crawlers = append(report.Crawlers, CrawlerAccess{
+			UserAgent:  robots.ProductToken(userAgent),
+			Allowed:    file.allows(userAgent, pageURL),
+			CrawlDelay: file.crawlDelay(userAgent),
+		})
+	}
+	return report
+}
+
+// politely applies the robots.txt of the site of rawURL when analyser.RespectRobots is set: its
+// Crawl-delay is kept to from now on, and false is returned if it disallows the URL. The caller
+// must hold a slot of the host from analyser.Hosts.
+func (analyser *DefaultAnalyzer) politely(ctx context.Context, rawURL string) bool {
+	if !analyser.RespectRobots {
+		return true
+	}
+	file := analyser.robots(ctx, rawURL)
+	analyser.Hosts.crawlDelay(hostKey(rawURL), file.crawlDelay(analyser.UserAgent))
+	return file.allows(analyser.UserAgent, rawURL)
+}
@@ -0,0 +1,212 @@
+package analyzer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRobots = `User-agent: *
+Disallow: /private
+Crawl-delay: 0.1
+
+User-agent: GPTBot
+Disallow: /
+`
+
+// newRobotsClient serves testRobots as robots.txt and a page with the given links everywhere else,
+// counting the requests made for each path.
+func newRobotsClient(page string, requested map[string]int, mutex *sync.Mutex) *mockHTTPClient {
+	return &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			mutex.Lock()
+			requested[req.URL.Path]++
+			mutex.Unlock()
+
+			body := page
+			if req.URL.Path == "/robots.txt" {
+				body = testRobots
+			}
+			return &http.Response{StatusCode: 200, Header: http.Header{"Content-Type": {"text/html"}}, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}
+}
+
+func TestAnalyze_RobotsReport(t *testing.T) {
+	var userAgent atomic.Value
+	var mutex sync.Mutex
+	client := newRobotsClient(`<a href="/about">About</a>`, map[string]int{}, &mutex)
+	serve := client.DoFunc
+	client.DoFunc = func(req *http.Request) (*http.Response, error) {
+		userAgent.Store(req.Header.Get("User-Agent"))
+		return serve(req)
+	}
+
+	report, err := NewAnalyzer(client).Analyze(context.Background(), "https://example.com/private/page")
+
+	require.NoError(t, err)
+	assert.Equal(t, DefaultUserAgent, userAgent.Load())
+	require.NotNil(t, report.Robots)
+	assert.Equal(t, "https://example.com/robots.txt", report.Robots.URL)
+	assert.Equal(t, 200, report.Robots.StatusCode)
+	require.Len(t, report.Robots.Crawlers, len(CommonCrawlers)+1)
+	assert.Equal(t, CrawlerAccess{UserAgent: "gogeturl", Allowed: false, CrawlDelay: 100 * time.Millisecond}, report.Robots.Crawlers[0])
+	for _, crawler := range report.Robots.Crawlers {
+		assert.False(t, crawler.Allowed, crawler.UserAgent)
+	}
+
+	// Links are still checked when robots.txt is only reported
+	assert.Equal(t, OutcomeOK, report.LinkResults[0].Outcome)
+}
+
+func TestAnalyze_RespectsRobots(t *testing.T) {
+	requested := map[string]int{}
+	var mutex sync.Mutex
+	page := `<a href="/private/one">One</a><a href="/private/two">Two</a><a href="/a">A</a><a href="/b">B</a><a href="/c">C</a>`
+	analyser := NewAnalyzer(newRobotsClient(page, requested, &mutex)).(*DefaultAnalyzer)
+	analyser.RespectRobots = true
+
+	start := time.Now()
+	report, err := analyser.Analyze(context.Background(), "https://example.com/")
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, requested["/robots.txt"])
+	assert.Zero(t, requested["/private/one"])
+	assert.Zero(t, requested["/private/two"])
+	for _, link := range report.LinkResults[:2] {
+		assert.Equal(t, OutcomeBlockedByRobots, link.Outcome)
+		assert.False(t, link.Broken)
+	}
+	assert.Zero(t, report.Links.Broken)
+	assert.Equal(t, map[Outcome]int{OutcomeBlockedByRobots: 2, OutcomeOK: 3}, report.Links.Outcomes)
+	assert.Equal(t, CacheStats{Misses: 3}, report.LinkCache)
+
+	// The page and its three allowed links are spaced out by the Crawl-delay
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+}
+
+func TestAnalyze_RespectsRobotsOverCachedLinks(t *testing.T) {
+	requested := map[string]int{}
+	var mutex sync.Mutex
+	page := `<a href="/private/one">One</a><a href="/public">Public</a>`
+	analyser := NewAnalyzer(newRobotsClient(page, requested, &mutex)).(*DefaultAnalyzer)
+
+	// A run that ignores robots.txt caches the disallowed link as working
+	_, err := analyser.Analyze(context.Background(), "https://example.com/")
+	require.NoError(t, err)
+	_, ok := analyser.Cache.get("https://example.com/private/one")
+	require.True(t, ok)
+
+	analyser.RespectRobots = true
+	report, err := analyser.Analyze(context.Background(), "https://example.com/")
+
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeBlockedByRobots, report.LinkResults[0].Outcome)
+	assert.False(t, report.LinkResults[0].Cached)
+	assert.Equal(t, OutcomeOK, report.LinkResults[1].Outcome)
+	assert.True(t, report.LinkResults[1].Cached)
+	assert.Equal(t, 1, requested["/private/one"])
+}
+
+func TestCrawler_RespectsRobots(t *testing.T) {
+	requested := map[string]int{}
+	var mutex sync.Mutex
+	page := `<title>Page</title><h1>Page</h1><a href="/private/area">Private</a><a href="/public">Public</a>`
+	analyser := NewAnalyzer(newRobotsClient(page, requested, &mutex)).(*DefaultAnalyzer)
+	analyser.RespectRobots = true
+	analyser.Hosts = NewHostLimiter(HostLimits{Concurrency: DefaultHostConcurrency})
+
+	report := NewCrawler(analyser, 2).Crawl(context.Background(), "https://example.com/", CrawlOptions{MaxDepth: 2, MaxPages: 10})
+
+	require.Len(t, report.Pages, 2)
+	assert.Equal(t, "https://example.com/public", report.Pages[1].URL)
+	assert.Equal(t, []string{"https://example.com/private/area"}, report.Disallowed)
+	assert.Zero(t, requested["/private/area"])
+}
+
+func TestRobotsCache_FetchesOncePerSite(t *testing.T) {
+	cache := NewRobotsCache(time.Minute)
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	fetch := func(context.Context) *robotsFile {
+		fetches.Add(1)
+		<-release
+		return &robotsFile{url: "https://example.com/robots.txt", statusCode: 404}
+	}
+
+	var waitGroup sync.WaitGroup
+	for range 5 {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			file := cache.file(context.Background(), "https://example.com", fetch)
+			assert.Equal(t, 404, file.statusCode)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	waitGroup.Wait()
+
+	assert.Equal(t, int32(1), fetches.Load())
+}
+
+func TestRobotsCache_OutlivesCancelledCaller(t *testing.T) {
+	cache := NewRobotsCache(time.Minute)
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) *robotsFile {
+		<-release
+		return &robotsFile{url: "https://example.com/robots.txt", statusCode: 200, err: ctx.Err()}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan *robotsFile)
+	go func() {
+		first <- cache.file(ctx, "https://example.com", fetch)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	assert.Nil(t, <-first)
+
+	close(release)
+	file := cache.file(context.Background(), "https://example.com", fetch)
+	require.NotNil(t, file)
+	assert.NoError(t, file.err)
+	assert.Equal(t, 200, file.statusCode)
+}
+
+func TestFetchRobots(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		allowed    bool
+		failed     bool
+	}{
+		{"found", 200, false, false},
+		{"missing", 404, true, false},
+		{"server error", 503, false, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			client := &mockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: testCase.statusCode, Status: http.StatusText(testCase.statusCode), Body: io.NopCloser(strings.NewReader(testRobots))}, nil
+				},
+			}
+			analyser := NewAnalyzer(client).(*DefaultAnalyzer)
+
+			file := analyser.fetchRobots(context.Background(), "https://example.com/robots.txt")
+
+			assert.Equal(t, testCase.allowed, file.allows(analyser.UserAgent, "https://example.com/private"))
+			assert.Equal(t, testCase.failed, file.err != nil)
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package analyzer
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Grade is the outcome of a single security check.
+type Grade string
+
+const (
+	GradePass Grade = "pass"
+	GradeWarn Grade = "warn"
+	GradeFail Grade = "fail"
+)
+
+// Names of the checks in SecurityReport.Checks, in the order they are reported.
+const (
+	CheckCSP                = "Content-Security-Policy"
+	CheckHSTS               = "Strict-Transport-Security"
+	CheckFraming            = "Framing"
+	CheckContentTypeOptions = "X-Content-Type-Options"
+	CheckReferrerPolicy     = "Referrer-Policy"
+	CheckPermissionsPolicy  = "Permissions-Policy"
+)
+
+// hstsMinMaxAge is the shortest HSTS max-age that is not flagged, 180 days in seconds.
+const hstsMinMaxAge = 180 * 24 * 60 * 60
+
+// SecurityReport grades the security headers and cookies the page was served with.
+type SecurityReport struct {
+	Checks  []SecurityCheck `json:"checks"`
+	Cookies []CookieCheck   `json:"cookies"`
+	// UnprotectedLogin is set when the page has a login form but is served without HSTS or without a CSP.
+	UnprotectedLogin bool `json:"unprotected_login"`
+}
+
+// SecurityCheck is the grade of one security header, with the value that was found.
+type SecurityCheck struct {
+	Name    string `json:"name"`
+	Value   string `json:"value,omitempty"`
+	Grade   Grade  `json:"grade"`
+	Message string `json:"message"`
+}
+
+// CookieCheck grades the flags of a cookie set by the page.
+type CookieCheck struct {
+	Name     string   `json:"name"`
+	Secure   bool     `json:"secure"`
+	HTTPOnly bool     `json:"http_only"`
+	SameSite string   `json:"same_site,omitempty"`
+	Grade    Grade    `json:"grade"`
+	Issues   []string `json:"issues,omitempty"`
+}
+
+// Check returns the check with the given name, or nil if it was not run.
+func (security SecurityReport) Check(name string) *SecurityCheck {
+	for index := range security.Checks {
+		if security.Checks[index].Name == name {
+			return &security.Checks[index]
+		}
+	}
+	return nil
+}
+
+// auditSecurity grades the response headers of a page served from pageURL.
+func auditSecurity(header http.Header, pageURL string, hasLoginForm bool) SecurityReport {
+	https := strings.HasPrefix(strings.ToLower(pageURL), "https://")
+	csp := header.Get("Content-Security-Policy")
+
+	report := SecurityReport{
+		Checks: []SecurityCheck{
+			checkCSP(csp, header.Get("Content-Security-Policy-Report-Only")),
+			checkHSTS(header.Get("Strict-Transport-Security"), https),
+			checkFraming(header.Get("X-Frame-Options"), csp),
+			checkContentTypeOptions(header.Get("X-Content-Type-Options")),
+			checkReferrerPolicy(header.Get("Referrer-Policy")),
+			checkPermissionsPolicy(header.Get("Permissions-Policy")),
+		},
+		Cookies: checkCookies(header.Values("Set-Cookie"), https),
+	}
+
+	if hasLoginForm {
+		report.UnprotectedLogin = report.Check(CheckHSTS).Grade == GradeFail || csp == ""
+	}
+	return report
+}
+
+func checkCSP(policy, reportOnly string) SecurityCheck {
+	check := SecurityCheck{Name: CheckCSP, Value: policy}
+	if policy == "" {
+		if reportOnly != "" {
+			check.Value = reportOnly
+			check.Grade = GradeWarn
+			check.Message = "The policy is only reported, not enforced."
+			return check
+		}
+		check.Grade = GradeFail
+		check.Message = "No Content-Security-Policy header; injected scripts run unrestricted."
+		return check
+	}
+
+	directives := parseDirectives(policy)
+	scripts, ok := directives["script-src"]
+	if !ok {
+		scripts, ok = directives["default-src"]
+	}
+	switch {
+	case !ok:
+		check.Grade = GradeWarn
+		check.Message = "The policy sets neither script-src nor default-src, so scripts are not restricted."
+	case containsToken(scripts, "'unsafe-inline'"), containsToken(scripts, "'unsafe-eval'"), containsToken(scripts, "*"):
+		check.Grade = GradeWarn
+		check.Message = "Scripts are allowed from any source, inline or through eval."
+	default:
+		check.Grade = GradePass
+		check.Message = "Scripts are restricted."
+	}
+	return check
+}
+
+func checkHSTS(value string, https bool) SecurityCheck {
+	check := SecurityCheck{Name: CheckHSTS, Value: value}
+	if !https {
+		check.Grade = GradeFail
+		check.Message = "The page is served over plain HTTP, where HSTS cannot apply."
+		return check
+	}
+	if value == "" {
+		check.Grade = GradeFail
+		check.Message = "No Strict-Transport-Security header; the first visit can be downgraded to HTTP."
+		return check
+	}
+
+	maxAge, err := hstsMaxAge(value)
+	switch {
+	case err != nil || maxAge <= 0:
+		check.Grade = GradeFail
+		check.Message = "max-age is missing or zero, which disables HSTS."
+	case maxAge < hstsMinMaxAge:
+		check.Grade = GradeWarn
+		check.Message = "max-age is shorter than 180 days."
+	default:
+		check.Grade = GradePass
+		check.Message = "HTTPS is enforced."
+	}
+	return check
+}
+
+func checkFraming(frameOptions, csp string) SecurityCheck {
+	check := SecurityCheck{Name: CheckFraming, Value: frameOptions}
+	if ancestors, ok := parseDirectives(csp)["frame-ancestors"]; ok {
+		check.Value = "frame-ancestors " + strings.Join(ancestors, " ")
+		if containsToken(ancestors, "*") {
+			check.Grade = GradeWarn
+			check.Message = "frame-ancestors allows any site to frame the page."
+			return check
+		}
+		check.Grade = GradePass
+		check.Message = "Framing is restricted by frame-ancestors."
+		return check
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(frameOptions)) {
+	case "DENY", "SAMEORIGIN":
+		check.Grade = GradePass
+		check.Message = "Framing is restricted by X-Frame-Options."
+	case "":
+		check.Grade = GradeFail
+		check.Message = "Neither X-Frame-Options nor frame-ancestors is set; the page can be framed for clickjacking."
+	default:
+		check.Grade = GradeWarn
+		check.Message = "X-Frame-Options has a value browsers ignore; use DENY, SAMEORIGIN or frame-ancestors."
+	}
+	return check
+}
+
+func checkContentTypeOptions(value string) SecurityCheck {
+	check := SecurityCheck{Name: CheckContentTypeOptions, Value: value}
+	if strings.EqualFold(strings.TrimSpace(value), "nosniff") {
+		check.Grade = GradePass
+		check.Message = "MIME type sniffing is disabled."
+		return check
+	}
+	check.Grade = GradeFail
+	check.Message = "Set to nosniff to stop browsers guessing content types."
+	return check
+}
+
+func checkReferrerPolicy(value string) SecurityCheck {
+	check := SecurityCheck{Name: CheckReferrerPolicy, Value: value}
+
+	// Several comma-separated policies may be given for fallback; the last one a browser knows wins
+	policies := strings.Split(value, ",")
+	policy := strings.ToLower(strings.TrimSpace(policies[len(policies)-1]))
+	switch policy {
+	case "":
+		check.Grade = GradeWarn
+		check.Message = "Not set; browsers fall back to strict-origin-when-cross-origin."
+	case "unsafe-url", "no-referrer-when-downgrade":
+		check.Grade = GradeFail
+		check.Message = "Full URLs, including paths and query strings, are sent to other sites."
+	default:
+		check.Grade = GradePass
+		check.Message = "Referrer information is limited."
+	}
+	return check
+}
+
+func checkPermissionsPolicy(value string) SecurityCheck {
+	check := SecurityCheck{Name: CheckPermissionsPolicy, Value: value}
+	if strings.TrimSpace(value) == "" {
+		check.Grade = GradeWarn
+		check.Message = "Not set; embedded content may request features such as camera or geolocation."
+		return check
+	}
+	check.Grade = GradePass
+	check.Message = "Browser features are restricted."
+	return check
+}
+
+// checkCookies grades the Secure, HttpOnly and SameSite flags of each Set-Cookie header.
+func checkCookies(setCookies []string, https bool) []CookieCheck {
+	var checks []CookieCheck
+	for _, line := range setCookies {
+		cookie, err := http.ParseSetCookie(line)
+		if err != nil {
+			continue
+		}
+
+		check := CookieCheck{Name: cookie.Name, Secure: cookie.Secure, HTTPOnly: cookie.HttpOnly, Grade: GradePass}
+		switch cookie.SameSite {
+		case http.SameSiteStrictMode:
+			check.SameSite = "Strict"
+		case http.SameSiteLaxMode:
+			check.SameSite = "Lax"
+		case http.SameSiteNoneMode:
+			check.SameSite = "None"
+		}
+
+		if !cookie.Secure {
+			check.fail("not Secure, so it is also sent over plain HTTP")
+		}
+		if check.SameSite == "None" && !cookie.Secure {
+			check.fail("SameSite=None without Secure is rejected by browsers")
+		}
+		if !cookie.HttpOnly {
+			check.warn("not HttpOnly, so scripts can read it")
+		}
+		if check.SameSite == "" {
+			check.warn("no SameSite attribute")
+		}
+		if !https && cookie.Secure {
+			check.warn("Secure cookie set over plain HTTP is ignored")
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+func (check *CookieCheck) fail(issue string) {
+	check.Grade = GradeFail
+	check.Issues = append(check.Issues, issue)
+}
+
+func (check *CookieCheck) warn(issue string) {
+	if check.Grade == GradePass {
+		check.Grade = GradeWarn
+	}
+	check.Issues = append(check.Issues, issue)
+}
+
+// hstsMaxAge returns the max-age directive of a Strict-Transport-Security value.
+func hstsMaxAge(value string) (int, error) {
+	for _, directive := range strings.Split(value, ";") {
+		name, maxAge, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if strings.EqualFold(strings.TrimSpace(name), "max-age") {
+			return strconv.Atoi(strings.Trim(strings.TrimSpace(maxAge), `"`))
+		}
+	}
+	return 0, strconv.ErrSyntax
+}
+
+// parseDirectives splits a Content-Security-Policy into its directives, keyed by lowercased name.
+// As in browsers, only the first occurrence of a directive counts.
+func parseDirectives(policy string) map[string][]string {
+	directives := map[string][]string{}
+	for _, directive := range strings.Split(policy, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if _, seen := directives[name]; !seen {
+			directives[name] = fields[1:]
+		}
+	}
+	return directives
+}
+
+func containsToken(tokens []string, token string) bool {
+	for _, candidate := range tokens {
+		if strings.EqualFold(candidate, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditSecurity_Headers(t *testing.T) {
+	tests := []struct {
+		name     string
+		check    string
+		header   http.Header
+		pageURL  string
+		expected Grade
+	}{
+		{"CSP missing", CheckCSP, http.Header{}, "https://example.com", GradeFail},
+		{"CSP report only", CheckCSP, http.Header{"Content-Security-Policy-Report-Only": {"default-src 'self'"}}, "https://example.com", GradeWarn},
+		{"CSP unsafe-inline", CheckCSP, http.Header{"Content-Security-Policy": {"default-src 'self'; script-src 'self' 'unsafe-inline'"}}, "https://example.com", GradeWarn},
+		{"CSP without script restriction", CheckCSP, http.Header{"Content-Security-Policy": {"img-src 'self'"}}, "https://example.com", GradeWarn},
+		{"CSP strict", CheckCSP, http.Header{"Content-Security-Policy": {"default-src 'self'; script-src 'self' 'sha256-abc='"}}, "https://example.com", GradePass},
+		{"HSTS over HTTP", CheckHSTS, http.Header{"Strict-Transport-Security": {"max-age=63072000"}}, "http://example.com", GradeFail},
+		{"HSTS missing", CheckHSTS, http.Header{}, "https://example.com", GradeFail},
+		{"HSTS zero max-age", CheckHSTS, http.Header{"Strict-Transport-Security": {"max-age=0"}}, "https://example.com", GradeFail},
+		{"HSTS short max-age", CheckHSTS, http.Header{"Strict-Transport-Security": {"max-age=86400"}}, "https://example.com", GradeWarn},
+		{"HSTS long max-age", CheckHSTS, http.Header{"Strict-Transport-Security": {"max-age=63072000; includeSubDomains; preload"}}, "https://example.com", GradePass},
+		{"framing missing", CheckFraming, http.Header{}, "https://example.com", GradeFail},
+		{"X-Frame-Options DENY", CheckFraming, http.Header{"X-Frame-Options": {"DENY"}}, "https://example.com", GradePass},
+		{"X-Frame-Options ALLOW-FROM", CheckFraming, http.Header{"X-Frame-Options": {"ALLOW-FROM https://a.example"}}, "https://example.com", GradeWarn},
+		{"frame-ancestors", CheckFraming, http.Header{"Content-Security-Policy": {"frame-ancestors 'none'"}}, "https://example.com", GradePass},
+		{"frame-ancestors wildcard", CheckFraming, http.Header{"Content-Security-Policy": {"frame-ancestors *"}, "X-Frame-Options": {"DENY"}}, "https://example.com", GradeWarn},
+		{"nosniff", CheckContentTypeOptions, http.Header{"X-Content-Type-Options": {"nosniff"}}, "https://example.com", GradePass},
+		{"nosniff missing", CheckContentTypeOptions, http.Header{}, "https://example.com", GradeFail},
+		{"referrer missing", CheckReferrerPolicy, http.Header{}, "https://example.com", GradeWarn},
+		{"referrer unsafe-url", CheckReferrerPolicy, http.Header{"Referrer-Policy": {"unsafe-url"}}, "https://example.com", GradeFail},
+		{"referrer fallback list", CheckReferrerPolicy, http.Header{"Referrer-Policy": {"no-referrer, strict-origin-when-cross-origin"}}, "https://example.com", GradePass},
+		{"permissions missing", CheckPermissionsPolicy, http.Header{}, "https://example.com", GradeWarn},
+		{"permissions set", CheckPermissionsPolicy, http.Header{"Permissions-Policy": {"camera=(), geolocation=()"}}, "https://example.com", GradePass},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			report := auditSecurity(testCase.header, testCase.pageURL, false)
+			check := report.Check(testCase.check)
+			if assert.NotNil(t, check) {
+				assert.Equal(t, testCase.expected, check.Grade, check.Message)
+			}
+			assert.False(t, report.UnprotectedLogin)
+		})
+	}
+}
+
+func TestAuditSecurity_Cookies(t *testing.T) {
+	header := http.Header{"Set-Cookie": {
+		"session=abc; Path=/; Secure; HttpOnly; SameSite=Lax",
+		"theme=dark; Path=/",
+		"tracking=1; SameSite=None",
+	}}
+
+	cookies := auditSecurity(header, "https://example.com", false).Cookies
+
+	if assert.Len(t, cookies, 3) {
+		assert.Equal(t, CookieCheck{Name: "session", Secure: true, HTTPOnly: true, SameSite: "Lax", Grade: GradePass}, cookies[0])
+
+		assert.Equal(t, GradeFail, cookies[1].Grade)
+		assert.Len(t, cookies[1].Issues, 3)
+
+		assert.Equal(t, "None", cookies[2].SameSite)
+		assert.Equal(t, GradeFail, cookies[2].Grade)
+		assert.Contains(t, cookies[2].Issues, "SameSite=None without Secure is rejected by browsers")
+	}
+}
+
+func TestAnalyze_FlagsUnprotectedLogin(t *testing.T) {
+	loginPage := `<form><input type="password"></form>`
+	tests := []struct {
+		name        string
+		header      http.Header
+		unprotected bool
+	}{
+		{"no HSTS or CSP", http.Header{}, true},
+		{"HSTS only", http.Header{"Strict-Transport-Security": {"max-age=63072000"}}, true},
+		{"CSP only", http.Header{"Content-Security-Policy": {"default-src 'self'"}}, true},
+		{"HSTS and CSP", http.Header{"Strict-Transport-Security": {"max-age=63072000"}, "Content-Security-Policy": {"default-src 'self'"}}, false},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			client := &mockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					header := testCase.header.Clone()
+					header.Set("Content-Type", "text/html")
+					return &http.Response{StatusCode: 200, Header: header, Body: io.NopCloser(strings.NewReader(loginPage))}, nil
+				},
+			}
+
+			report, err := NewAnalyzer(client).Analyze(context.Background(), "https://example.com/login")
+
+			assert.NoError(t, err)
+			assert.True(t, report.HasLoginForm)
+			assert.Equal(t, testCase.unprotected, report.Security.UnprotectedLogin)
+		})
+	}
+}
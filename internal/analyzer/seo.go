@@ -0,0 +1,367 @@
+package analyzer
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Names of the checks in SEOReport.Checks, in the order they are reported.
+const (
+	CheckTitle       = "Title"
+	CheckDescription = "Meta Description"
+	CheckCanonical   = "Canonical"
+	CheckIndexing    = "Indexing"
+	CheckHreflang    = "Hreflang"
+	CheckViewport    = "Viewport"
+)
+
+// Sources of an IndexingDirective.
+const (
+	DirectiveMeta   = "meta"
+	DirectiveHeader = "X-Robots-Tag"
+)
+
+// The lengths, in characters, outside which titles and descriptions are flagged. Longer ones are
+// cut off in search results; shorter ones rarely describe the page well enough.
+const (
+	titleMinLength       = 30
+	titleMaxLength       = 60
+	descriptionMinLength = 70
+	descriptionMaxLength = 160
+)
+
+// hreflangPattern matches a language code, optionally with a script and a region, or x-default.
+var hreflangPattern = regexp.MustCompile(`(?i)^(x-default|[a-z]{2,3}(-[a-z]{4})?(-([a-z]{2}|[0-9]{3}))?)$`)
+
+// SEOReport is the metadata search engines read from the page, graded by checks such as title
+// length and whether the page may be indexed.
+type SEOReport struct {
+	Description string `json:"description,omitempty"`
+	// Canonical is the first canonical URL the page declares, resolved against the page.
+	Canonical  string              `json:"canonical,omitempty"`
+	Alternates []Alternate         `json:"alternates,omitempty"`
+	Viewport   string              `json:"viewport,omitempty"`
+	Directives []IndexingDirective `json:"directives,omitempty"`
+	// Indexable is false when a robots meta tag or X-Robots-Tag header asks search engines not to index the page.
+	Indexable bool       `json:"indexable"`
+	Checks    []SEOCheck `json:"checks"`
+}
+
+// Alternate is a version of the page in another language, declared by a hreflang link.
+type Alternate struct {
+	HrefLang string `json:"hreflang"`
+	URL      string `json:"url"`
+}
+
+// IndexingDirective is a robots meta tag or X-Robots-Tag header, such as "noindex, nofollow".
+// UserAgent is empty when it applies to every crawler.
+type IndexingDirective struct {
+	Source     string   `json:"source"`
+	UserAgent  string   `json:"user_agent,omitempty"`
+	Directives []string `json:"directives"`
+}
+
+// SEOCheck is the grade of one piece of metadata, with the value that was found.
+type SEOCheck struct {
+	Name    string `json:"name"`
+	Value   string `json:"value,omitempty"`
+	Grade   Grade  `json:"grade"`
+	Message string `json:"message"`
+}
+
+// Check returns the check with the given name, or nil if it was not run.
+func (seo SEOReport) Check(name string) *SEOCheck {
+	for index := range seo.Checks {
+		if seo.Checks[index].Name == name {
+			return &seo.Checks[index]
+		}
+	}
+	return nil
+}
+
+// auditSEO grades the metadata collected from a page served from pageURL with the given title and response headers.
+func auditSEO(metadata *seoCollector, title string, header http.Header, pageURL string) SEOReport {
+	base, _ := url.Parse(pageURL)
+	canonicals := make([]string, len(metadata.canonicals))
+	for index, canonical := range metadata.canonicals {
+		canonicals[index] = resolveAgainst(base, canonical)
+	}
+	alternates := make([]Alternate, len(metadata.alternates))
+	for index, alternate := range metadata.alternates {
+		alternates[index] = Alternate{HrefLang: alternate.HrefLang, URL: resolveAgainst(base, alternate.URL)}
+	}
+	directives := append(metadata.directives[:len(metadata.directives):len(metadata.directives)], parseRobotsHeader(header.Values("X-Robots-Tag"))...)
+
+	report := SEOReport{
+		Alternates: alternates,
+		Directives: directives,
+		Checks: []SEOCheck{
+			checkTitle(title, metadata.titles),
+			checkDescription(metadata.descriptions),
+			checkCanonical(canonicals, pageURL),
+			checkIndexing(directives),
+			checkHreflang(alternates, pageURL),
+			checkViewport(metadata.viewports),
+		},
+	}
+	if len(metadata.descriptions) > 0 {
+		report.Description = metadata.descriptions[0]
+	}
+	if len(canonicals) > 0 {
+		report.Canonical = canonicals[0]
+	}
+	if len(metadata.viewports) > 0 {
+		report.Viewport = metadata.viewports[0]
+	}
+	report.Indexable = report.Check(CheckIndexing).Grade != GradeFail
+	return report
+}
+
+func checkTitle(title string, count int) SEOCheck {
+	title = strings.Join(strings.Fields(title), " ")
+	check := SEOCheck{Name: CheckTitle, Value: title}
+	length := utf8.RuneCountInString(title)
+	switch {
+	case title == "" || count == 0:
+		check.Grade = GradeFail
+		check.Message = "The page has no title, so search engines make one up."
+	case count > 1:
+		check.Grade = GradeWarn
+		check.Message = fmt.Sprintf("The page has %d <title> elements; only the first is used.", count)
+	case length < titleMinLength:
+		check.Grade = GradeWarn
+		check.Message = fmt.Sprintf("At %d characters the title is short; aim for %d to %d.", length, titleMinLength, titleMaxLength)
+	case length > titleMaxLength:
+		check.Grade = GradeWarn
+		check.Message = fmt.Sprintf("At %d characters the title is likely cut off in search results; aim for %d to %d.", length, titleMinLength, titleMaxLength)
+	default:
+		check.Grade = GradePass
+		check.Message = "The title has a good length."
+	}
+	return check
+}
+
+func checkDescription(descriptions []string) SEOCheck {
+	check := SEOCheck{Name: CheckDescription}
+	if len(descriptions) > 0 {
+		check.Value = strings.Join(strings.Fields(descriptions[0]), " ")
+	}
+	length := utf8.RuneCountInString(check.Value)
+	switch {
+	case check.Value == "":
+		check.Grade = GradeWarn
+		check.Message = "No meta description; search engines pick a snippet from the page instead."
+	case len(descriptions) > 1:
+		check.Grade = GradeWarn
+		check.Message = fmt.Sprintf("The page has %d meta descriptions; only one is used.", len(descriptions))
+	case length < descriptionMinLength:
+		check.Grade = GradeWarn
+		check.Message = fmt.Sprintf("At %d characters the description is short; aim for %d to %d.", length, descriptionMinLength, descriptionMaxLength)
+	case length > descriptionMaxLength:
+		check.Grade = GradeWarn
+		check.Message = fmt.Sprintf("At %d characters the description is likely cut off in search results; aim for %d to %d.", length, descriptionMinLength, descriptionMaxLength)
+	default:
+		check.Grade = GradePass
+		check.Message = "The description has a good length."
+	}
+	return check
+}
+
+func checkCanonical(canonicals []string, pageURL string) SEOCheck {
+	check := SEOCheck{Name: CheckCanonical}
+	if len(canonicals) == 0 {
+		check.Grade = GradeWarn
+		check.Message = "No canonical URL; copies of the page under other URLs may be indexed separately."
+		return check
+	}
+	check.Value = canonicals[0]
+
+	distinct := map[string]bool{}
+	for _, canonical := range canonicals {
+		distinct[comparableURL(canonical)] = true
+	}
+	switch {
+	case len(distinct) > 1:
+		check.Grade = GradeFail
+		check.Message = fmt.Sprintf("The page declares %d different canonical URLs, so search engines ignore them all.", len(distinct))
+	case comparableURL(canonicals[0]) != comparableURL(pageURL):
+		check.Grade = GradeWarn
+		check.Message = "The canonical URL points to another page, which search engines index instead of this one."
+	default:
+		check.Grade = GradePass
+		check.Message = "The canonical URL is the page itself."
+	}
+	return check
+}
+
+func checkIndexing(directives []IndexingDirective) SEOCheck {
+	check := SEOCheck{Name: CheckIndexing, Grade: GradePass, Message: "Search engines may index the page and follow its links."}
+	for _, directive := range directives {
+		// Only directives for every crawler, or for Googlebot as the crawler most sites care about, are graded
+		if directive.UserAgent != "" && directive.UserAgent != "googlebot" {
+			continue
+		}
+		for _, value := range directive.Directives {
+			switch value {
+			case "noindex", "none":
+				check.Value = strings.Join(directive.Directives, ", ")
+				check.Grade = GradeFail
+				check.Message = fmt.Sprintf("%s asks search engines not to index the page.", directive.describe())
+				return check
+			case "nofollow":
+				if check.Grade == GradePass {
+					check.Value = strings.Join(directive.Directives, ", ")
+					check.Grade = GradeWarn
+					check.Message = fmt.Sprintf("%s asks search engines not to follow the links of the page.", directive.describe())
+				}
+			}
+		}
+	}
+	return check
+}
+
+func checkHreflang(alternates []Alternate, pageURL string) SEOCheck {
+	check := SEOCheck{Name: CheckHreflang, Grade: GradePass}
+	if len(alternates) == 0 {
+		check.Message = "The page declares no language alternates."
+		return check
+	}
+
+	var languages []string
+	targets := map[string]string{}
+	selfReferenced := false
+	for _, alternate := range alternates {
+		languages = append(languages, alternate.HrefLang)
+		language := strings.ToLower(alternate.HrefLang)
+		switch target, seen := targets[language]; {
+		case !hreflangPattern.MatchString(alternate.HrefLang):
+			check.warn(fmt.Sprintf("%q is not a language code.", alternate.HrefLang))
+		case seen && target != comparableURL(alternate.URL):
+			check.warn(fmt.Sprintf("%q points to more than one URL.", alternate.HrefLang))
+		}
+		targets[language] = comparableURL(alternate.URL)
+		selfReferenced = selfReferenced || comparableURL(alternate.URL) == comparableURL(pageURL)
+	}
+	if !selfReferenced {
+		check.warn("The alternates do not include the page itself, so search engines may ignore them.")
+	}
+	check.Value = strings.Join(languages, ", ")
+	if check.Grade == GradePass {
+		check.Message = fmt.Sprintf("%d language alternates, including the page itself.", len(alternates))
+	}
+	return check
+}
+
+func checkViewport(viewports []string) SEOCheck {
+	check := SEOCheck{Name: CheckViewport}
+	if len(viewports) == 0 {
+		check.Grade = GradeWarn
+		check.Message = "No viewport meta tag; mobile browsers render the page at desktop width."
+		return check
+	}
+	check.Value = viewports[0]
+
+	properties := map[string]string{}
+	for _, property := range strings.FieldsFunc(strings.ToLower(check.Value), func(r rune) bool { return r == ',' || r == ';' }) {
+		key, value, _ := strings.Cut(property, "=")
+		properties[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	switch {
+	case properties["width"] != "device-width":
+		check.Grade = GradeWarn
+		check.Message = "The viewport does not set width=device-width, so the page does not adapt to mobile screens."
+	case properties["user-scalable"] == "no" || properties["user-scalable"] == "0" || properties["maximum-scale"] == "1" || properties["maximum-scale"] == "1.0":
+		check.Grade = GradeWarn
+		check.Message = "The viewport stops visitors from zooming in."
+	default:
+		check.Grade = GradePass
+		check.Message = "The page adapts to the width of the device."
+	}
+	return check
+}
+
+// warn downgrades a passing check and adds issue to its message.
+func (check *SEOCheck) warn(issue string) {
+	if check.Grade == GradePass {
+		check.Grade = GradeWarn
+		check.Message = issue
+		return
+	}
+	check.Message += " " + issue
+}
+
+// describe names where the directive came from, such as "The googlebot meta tag".
+func (directive IndexingDirective) describe() string {
+	if directive.Source == DirectiveHeader {
+		return "The X-Robots-Tag header"
+	}
+	return fmt.Sprintf("The %s meta tag", cmp.Or(directive.UserAgent, "robots"))
+}
+
+// robotsDirectivesWithValue are the directives written as "name: value", which must not be mistaken
+// for the user agent an X-Robots-Tag header may start with.
+var robotsDirectivesWithValue = map[string]bool{
+	"unavailable_after": true,
+	"max-snippet":       true,
+	"max-image-preview": true,
+	"max-video-preview": true,
+}
+
+// parseRobotsHeader reads X-Robots-Tag headers, such as "noindex, nofollow" or "googlebot: noindex".
+func parseRobotsHeader(values []string) []IndexingDirective {
+	var directives []IndexingDirective
+	for _, value := range values {
+		directive := IndexingDirective{Source: DirectiveHeader}
+		if name, rest, ok := strings.Cut(value, ":"); ok && !strings.Contains(name, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); !robotsDirectivesWithValue[name] {
+				directive.UserAgent, value = name, rest
+			}
+		}
+		if directive.Directives = splitDirectives(value); len(directive.Directives) > 0 {
+			directives = append(directives, directive)
+		}
+	}
+	return directives
+}
+
+// splitDirectives splits a comma-separated list of robots directives and lowercases them.
+func splitDirectives(content string) []string {
+	var directives []string
+	for _, directive := range strings.Split(content, ",") {
+		if directive = strings.ToLower(strings.TrimSpace(directive)); directive != "" {
+			directives = append(directives, directive)
+		}
+	}
+	return directives
+}
+
+// resolveAgainst resolves reference against base; a reference that cannot be parsed is returned as it is.
+func resolveAgainst(base *url.URL, reference string) string {
+	parsed, err := url.Parse(reference)
+	if err != nil || base == nil {
+		return reference
+	}
+	return base.ResolveReference(parsed).String()
+}
+
+// comparableURL normalizes the parts of a URL that do not change the page it identifies: the case
+// of the scheme and host, an empty path, and the fragment.
+func comparableURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	return parsed.String()
+}
@@ -0,0 +1,137 @@
+package analyzer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const seoPage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Pricing plans for small and large teams</title>
+	<meta name="description" content="Compare the plans, see what each one includes and start a free trial of the tier that suits your team.">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<meta name="robots" content="max-snippet:50, max-image-preview:large">
+	<link rel="canonical" href="/pricing">
+	<link rel="alternate" hreflang="en" href="https://example.com/pricing">
+	<link rel="alternate" hreflang="de" href="https://example.com/de/preise">
+	<link rel="alternate" hreflang="x-default" href="https://example.com/pricing">
+</head>
+<body>
+	<svg><title>Logo</title></svg>
+	<h1>Pricing</h1>
+</body>
+</html>`
+
+func auditPage(body string, header http.Header, pageURL string) SEOReport {
+	title := &titleCollector{}
+	metadata := &seoCollector{}
+	walkDocument(body, title, metadata)
+	return auditSEO(metadata, title.title, header, pageURL)
+}
+
+func TestAuditSEO_Metadata(t *testing.T) {
+	report := auditPage(seoPage, http.Header{}, "https://example.com/pricing")
+
+	assert.Equal(t, "Compare the plans, see what each one includes and start a free trial of the tier that suits your team.", report.Description)
+	assert.Equal(t, "https://example.com/pricing", report.Canonical)
+	assert.Equal(t, "width=device-width, initial-scale=1", report.Viewport)
+	assert.Equal(t, []Alternate{
+		{HrefLang: "en", URL: "https://example.com/pricing"},
+		{HrefLang: "de", URL: "https://example.com/de/preise"},
+		{HrefLang: "x-default", URL: "https://example.com/pricing"},
+	}, report.Alternates)
+	assert.Equal(t, []IndexingDirective{{Source: DirectiveMeta, Directives: []string{"max-snippet:50", "max-image-preview:large"}}}, report.Directives)
+	assert.True(t, report.Indexable)
+	for _, check := range report.Checks {
+		assert.Equal(t, GradePass, check.Grade, "%s: %s", check.Name, check.Message)
+	}
+}
+
+func TestAuditSEO_Checks(t *testing.T) {
+	tests := []struct {
+		name     string
+		check    string
+		body     string
+		header   http.Header
+		expected Grade
+	}{
+		{"title missing", CheckTitle, `<h1>Hi</h1>`, nil, GradeFail},
+		{"title only in svg", CheckTitle, `<svg><title>Logo</title></svg><h1>Hi</h1>`, nil, GradeFail},
+		{"title duplicated", CheckTitle, `<title>Pricing plans for small and large teams</title><title>Pricing</title>`, nil, GradeWarn},
+		{"title short", CheckTitle, `<title>Pricing</title>`, nil, GradeWarn},
+		{"title long", CheckTitle, `<title>` + strings.Repeat("Pricing ", 10) + `</title>`, nil, GradeWarn},
+		{"description missing", CheckDescription, ``, nil, GradeWarn},
+		{"description empty", CheckDescription, `<meta name="description" content=" ">`, nil, GradeWarn},
+		{"description short", CheckDescription, `<meta name="description" content="Our plans.">`, nil, GradeWarn},
+		{"description long", CheckDescription, `<meta name="description" content="` + strings.Repeat("Plans ", 30) + `">`, nil, GradeWarn},
+		{"description duplicated", CheckDescription, `<meta name="description" content="` + strings.Repeat("Plans ", 15) + `"><meta name="Description" content="Other">`, nil, GradeWarn},
+		{"canonical missing", CheckCanonical, ``, nil, GradeWarn},
+		{"canonical elsewhere", CheckCanonical, `<link rel="canonical" href="https://example.com/">`, nil, GradeWarn},
+		{"canonical self with fragment", CheckCanonical, `<link rel="canonical" href="HTTPS://Example.com/pricing#plans">`, nil, GradePass},
+		{"canonical conflicting", CheckCanonical, `<link rel="canonical" href="/pricing"><link rel="canonical" href="/plans">`, nil, GradeFail},
+		{"canonical through base", CheckCanonical, `<base href="https://example.com/"><link rel="canonical" href="pricing">`, nil, GradePass},
+		{"noindex meta", CheckIndexing, `<meta name="robots" content="NOINDEX, follow">`, nil, GradeFail},
+		{"none googlebot meta", CheckIndexing, `<meta name="googlebot" content="none">`, nil, GradeFail},
+		{"nofollow meta", CheckIndexing, `<meta name="robots" content="nofollow">`, nil, GradeWarn},
+		{"noindex header", CheckIndexing, ``, http.Header{"X-Robots-Tag": {"noindex"}}, GradeFail},
+		{"noindex header for googlebot", CheckIndexing, ``, http.Header{"X-Robots-Tag": {"googlebot: noindex"}}, GradeFail},
+		{"noindex header for another crawler", CheckIndexing, ``, http.Header{"X-Robots-Tag": {"otherbot: noindex"}}, GradePass},
+		{"unavailable_after header", CheckIndexing, ``, http.Header{"X-Robots-Tag": {"unavailable_after: 25 Jun 2030 15:00:00 PST"}}, GradePass},
+		{"hreflang none", CheckHreflang, ``, nil, GradePass},
+		{"hreflang without self", CheckHreflang, `<link rel="alternate" hreflang="de" href="/de/preise">`, nil, GradeWarn},
+		{"hreflang invalid code", CheckHreflang, `<link rel="alternate" hreflang="english" href="/pricing">`, nil, GradeWarn},
+		{"hreflang conflicting", CheckHreflang, `<link rel="alternate" hreflang="en" href="/pricing"><link rel="alternate" hreflang="EN" href="/plans">`, nil, GradeWarn},
+		{"hreflang with region", CheckHreflang, `<link rel="alternate" hreflang="en-GB" href="/pricing"><link rel="alternate" hreflang="es-419" href="/es/precios">`, nil, GradePass},
+		{"viewport missing", CheckViewport, ``, nil, GradeWarn},
+		{"viewport fixed width", CheckViewport, `<meta name="viewport" content="width=1024">`, nil, GradeWarn},
+		{"viewport without zoom", CheckViewport, `<meta name="viewport" content="width=device-width, user-scalable=no">`, nil, GradeWarn},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			header := testCase.header
+			if header == nil {
+				header = http.Header{}
+			}
+			report := auditPage(testCase.body, header, "https://example.com/pricing")
+			check := report.Check(testCase.check)
+			if assert.NotNil(t, check) {
+				assert.Equal(t, testCase.expected, check.Grade, check.Message)
+			}
+			assert.Equal(t, report.Check(CheckIndexing).Grade != GradeFail, report.Indexable)
+		})
+	}
+}
+
+func TestParseRobotsHeader(t *testing.T) {
+	directives := parseRobotsHeader([]string{"noindex, nofollow", "GoogleBot: noarchive", "max-snippet: 20", " "})
+
+	assert.Equal(t, []IndexingDirective{
+		{Source: DirectiveHeader, Directives: []string{"noindex", "nofollow"}},
+		{Source: DirectiveHeader, UserAgent: "googlebot", Directives: []string{"noarchive"}},
+		{Source: DirectiveHeader, Directives: []string{"max-snippet: 20"}},
+	}, directives)
+}
+
+func TestAnalyze_ReportsSEO(t *testing.T) {
+	client := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"Content-Type": {"text/html"}, "X-Robots-Tag": {"noindex"}}
+			return &http.Response{StatusCode: 200, Header: header, Body: io.NopCloser(strings.NewReader(seoPage))}, nil
+		},
+	}
+
+	report, err := NewAnalyzer(client).Analyze(context.Background(), "https://example.com/pricing")
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/pricing", report.SEO.Canonical)
+	assert.False(t, report.SEO.Indexable)
+	assert.Equal(t, "The X-Robots-Tag header asks search engines not to index the page.", report.SEO.Check(CheckIndexing).Message)
+}
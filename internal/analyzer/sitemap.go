@@ -0,0 +1,247 @@
+package analyzer
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gayansanjeewa/gogeturl/internal/sitemap"
+)
+
+const (
+	// DefaultSitemapURLs is the number of pages of a sitemap that are analyzed unless told otherwise.
+	DefaultSitemapURLs = 100
+	// maxSitemapFiles bounds how many sitemap files, indexes included, one discovery reads.
+	maxSitemapFiles = 50
+)
+
+// Kinds of SitemapIssue.
+const (
+	SitemapNon2xx   = "non_2xx"
+	SitemapRedirect = "redirect"
+)
+
+// ErrNoSitemap is returned when no sitemap of a site lists any page.
+var ErrNoSitemap = errors.New("no sitemap listing any page was found")
+
+// SitemapFinder finds the pages a site lists in its sitemaps.
+type SitemapFinder interface {
+	FindSitemapURLs(ctx context.Context, siteURL string, limit int) (*SitemapListing, error)
+}
+
+// SitemapFile is one sitemap that was read, with the number of pages, or of sitemaps for an index, it lists.
+type SitemapFile struct {
+	URL   string `json:"url"`
+	Index bool   `json:"index"`
+	URLs  int    `json:"urls"`
+	Error string `json:"error,omitempty"`
+}
+
+// SitemapListing is what sitemap discovery found: every sitemap read and the distinct pages they list.
+type SitemapListing struct {
+	Sitemaps []SitemapFile
+	Entries  []sitemap.Entry
+	// Truncated is set when the sitemaps list more pages, or more sitemaps, than were taken.
+	Truncated bool
+}
+
+// SitemapIssue is a sitemap entry that did not respond with a 2xx status directly. Sitemaps should
+// only list the final URLs of working pages.
+type SitemapIssue struct {
+	URL          string `json:"url"`
+	Kind         string `json:"kind"`
+	StatusCode   int    `json:"status_code"`
+	RedirectedTo string `json:"redirected_to,omitempty"`
+}
+
+// SitemapReport is the result of analyzing every page listed in the sitemaps of a site.
+type SitemapReport struct {
+	Sitemaps []SitemapFile   `json:"sitemaps"`
+	Entries  []sitemap.Entry `json:"entries"`
+	Issues   []SitemapIssue  `json:"issues"`
+	Batch    *BatchReport    `json:"batch"`
+	// Truncated is set when the sitemaps list more pages than were analyzed.
+	Truncated bool `json:"truncated"`
+}
+
+// RunSitemap analyzes up to limit pages that finder finds in the sitemaps of siteURL as one batch,
+// and flags the entries that responded with a non-2xx status or a redirect.
+func (runner *BatchRunner) RunSitemap(ctx context.Context, finder SitemapFinder, siteURL string, limit int) (*SitemapReport, error) {
+	listing, err := finder.FindSitemapURLs(ctx, siteURL, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, len(listing.Entries))
+	for index, entry := range listing.Entries {
+		urls[index] = entry.URL
+	}
+	report := &SitemapReport{
+		Sitemaps:  listing.Sitemaps,
+		Entries:   listing.Entries,
+		Batch:     runner.Run(ctx, urls),
+		Truncated: listing.Truncated,
+	}
+
+	for _, result := range report.Batch.Results {
+		if result.Report == nil {
+			continue
+		}
+		status, hops := result.Report.Response.StatusCode, result.Report.Redirects.Hops
+		switch {
+		case status != 0 && (status < 200 || status >= 300):
+			report.Issues = append(report.Issues, SitemapIssue{URL: result.URL, Kind: SitemapNon2xx, StatusCode: status})
+		case len(hops) > 0:
+			report.Issues = append(report.Issues, SitemapIssue{
+				URL:          result.URL,
+				Kind:         SitemapRedirect,
+				StatusCode:   hops[0].StatusCode,
+				RedirectedTo: cmp.Or(result.Report.FinalURL, hops[len(hops)-1].Location),
+			})
+		}
+	}
+	return report, nil
+}
+
+// FindSitemapURLs lists up to limit distinct pages from the sitemaps of a site. siteURL is either a
+// sitemap itself, recognized by a path ending in .xml or .xml.gz, or any page of the site, whose
+// sitemaps are then taken from the Sitemap lines of its robots.txt or else from /sitemap.xml.
+// Sitemap indexes are followed. If no sitemap could be downloaded, other than for not existing, the
+// error of the first one is returned; otherwise ErrNoSitemap is returned if no page was found.
+func (analyser *DefaultAnalyzer) FindSitemapURLs(ctx context.Context, siteURL string, limit int) (*SitemapListing, error) {
+	parsed, err := url.Parse(siteURL)
+	if err != nil {
+		return nil, err
+	}
+	queue := []string{siteURL}
+	if path := strings.ToLower(parsed.Path); !strings.HasSuffix(path, ".xml") && !strings.HasSuffix(path, ".xml.gz") {
+		queue = analyser.discoverSitemaps(ctx, parsed)
+	}
+
+	listing := &SitemapListing{}
+	// answered is set once a sitemap was read or found not to exist; failed is the first that could not be downloaded
+	var answered bool
+	var failed error
+	var fetchErr *sitemapFetchError
+	seenFiles := map[string]bool{}
+	seenPages := map[string]bool{}
+	for len(queue) > 0 && !listing.Truncated && ctx.Err() == nil {
+		fileURL := queue[0]
+		queue = queue[1:]
+		if seenFiles[fileURL] {
+			continue
+		}
+		seenFiles[fileURL] = true
+		if len(listing.Sitemaps) == maxSitemapFiles {
+			listing.Truncated = true
+			break
+		}
+
+		file := SitemapFile{URL: fileURL}
+		parsedMap, err := analyser.fetchSitemap(ctx, fileURL)
+		if !errors.As(err, &fetchErr) || missingSitemap(err) {
+			answered = true
+		} else if failed == nil {
+			failed = fmt.Errorf("%s: %w", fileURL, err)
+		}
+		switch {
+		case err != nil:
+			file.Error = err.Error()
+		case parsedMap.Index:
+			file.Index, file.URLs = true, len(parsedMap.Sitemaps)
+			base, _ := url.Parse(fileURL)
+			for _, child := range parsedMap.Sitemaps {
+				if resolved, err := base.Parse(child); err == nil {
+					queue = append(queue, resolved.String())
+				}
+			}
+		default:
+			file.URLs = len(parsedMap.URLs)
+			for _, entry := range parsedMap.URLs {
+				if seenPages[entry.URL] {
+					continue
+				}
+				if len(listing.Entries) == limit {
+					listing.Truncated = true
+					break
+				}
+				seenPages[entry.URL] = true
+				listing.Entries = append(listing.Entries, entry)
+			}
+		}
+		listing.Sitemaps = append(listing.Sitemaps, file)
+	}
+
+	if len(listing.Entries) == 0 {
+		if err := ctx.Err(); err != nil {
+			return listing, err
+		}
+		if !answered && failed != nil {
+			return listing, failed
+		}
+		for _, file := range listing.Sitemaps {
+			if file.Error != "" {
+				return listing, fmt.Errorf("%w: %s: %s", ErrNoSitemap, file.URL, file.Error)
+			}
+		}
+		return listing, ErrNoSitemap
+	}
+	return listing, nil
+}
+
+// discoverSitemaps returns the sitemaps the robots.txt of a site lists, resolved against it, or its
+// /sitemap.xml if it lists none.
+func (analyser *DefaultAnalyzer) discoverSitemaps(ctx context.Context, site *url.URL) []string {
+	origin := site.Scheme + "://" + site.Host
+	file := analyser.robots(ctx, site.String())
+	if analyser.Robots == nil {
+		file = analyser.fetchRobots(ctx, origin+"/robots.txt")
+	}
+
+	base, _ := url.Parse(origin + "/robots.txt")
+	var sitemaps []string
+	for _, listed := range file.sitemaps() {
+		if parsed, err := url.Parse(listed); err == nil {
+			sitemaps = append(sitemaps, base.ResolveReference(parsed).String())
+		}
+	}
+	if len(sitemaps) > 0 {
+		return sitemaps
+	}
+	return []string{origin + "/sitemap.xml"}
+}
+
+// sitemapFetchError is a sitemap that could not be downloaded, as opposed to one that could not be parsed.
+type sitemapFetchError struct {
+	err error
+}
+
+func (e *sitemapFetchError) Error() string { return e.err.Error() }
+func (e *sitemapFetchError) Unwrap() error { return e.err }
+
+// missingSitemap reports whether err means the sitemap does not exist.
+func missingSitemap(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone)
+}
+
+// fetchSitemap requests and parses one sitemap file, following redirects. Failures to download it
+// are returned as a *sitemapFetchError.
+func (analyser *DefaultAnalyzer) fetchSitemap(ctx context.Context, sitemapURL string) (*sitemap.Sitemap, error) {
+	header := http.Header{}
+	header.Set("Accept", "application/xml,text/xml;q=0.9,*/*;q=0.1")
+	resp, _, err := analyser.follow(ctx, http.MethodGet, sitemapURL, header, nil)
+	if err != nil {
+		return nil, &sitemapFetchError{err: err}
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &sitemapFetchError{err: &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}}
+	}
+	return sitemap.Parse(resp.Body)
+}
@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gayansanjeewa/gogeturl/internal/sitemap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSitemapClient serves a site whose robots.txt points at a sitemap index of one plain and one
+// gzipped urlset. Without robots.txt, the site falls back to /sitemap.xml.
+func newSitemapClient(t *testing.T, withRobots bool) *mockHTTPClient {
+	var posts bytes.Buffer
+	writer := gzip.NewWriter(&posts)
+	_, _ = writer.Write([]byte(`<urlset><url><loc>https://example.com/gone</loc></url><url><loc>https://example.com/</loc></url></urlset>`))
+	require.NoError(t, writer.Close())
+
+	files := map[string]string{
+		"/index.xml": `<sitemapindex><sitemap><loc>/pages.xml</loc></sitemap><sitemap><loc>https://example.com/posts.xml.gz</loc></sitemap>
+			<sitemap><loc>https://example.com/missing.xml</loc></sitemap></sitemapindex>`,
+		"/pages.xml":    `<urlset><url><loc>https://example.com/</loc><lastmod>2025-01-01</lastmod></url><url><loc>https://example.com/moved</loc></url></urlset>`,
+		"/posts.xml.gz": posts.String(),
+		"/sitemap.xml":  `<urlset><url><loc>https://example.com/</loc></url></urlset>`,
+	}
+	if withRobots {
+		files["/robots.txt"] = "User-agent: *\nDisallow:\nSitemap: https://example.com/index.xml\n"
+	}
+
+	return &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"Content-Type": {"text/html"}}
+			status, body := 200, "<title>Page</title>"
+			switch path := req.URL.Path; {
+			case path == "/moved":
+				status = http.StatusMovedPermanently
+				header.Set("Location", "/new")
+			case path == "/gone" || path == "/missing.xml":
+				status = http.StatusNotFound
+			case files[path] != "":
+				body = files[path]
+			case strings.HasSuffix(path, ".txt") || strings.HasSuffix(path, ".xml"):
+				status = http.StatusNotFound
+			}
+			return &http.Response{StatusCode: status, Status: http.StatusText(status), Header: header, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}
+}
+
+func TestFindSitemapURLs(t *testing.T) {
+	analyser := NewAnalyzer(newSitemapClient(t, true)).(*DefaultAnalyzer)
+
+	listing, err := analyser.FindSitemapURLs(context.Background(), "https://example.com/about", 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, []SitemapFile{
+		{URL: "https://example.com/index.xml", Index: true, URLs: 3},
+		{URL: "https://example.com/pages.xml", URLs: 2},
+		{URL: "https://example.com/posts.xml.gz", URLs: 2},
+		{URL: "https://example.com/missing.xml", Error: "received non-2xx status code: Not Found"},
+	}, listing.Sitemaps)
+	assert.Equal(t, []sitemap.Entry{
+		{URL: "https://example.com/", LastModified: "2025-01-01"},
+		{URL: "https://example.com/moved"},
+		{URL: "https://example.com/gone"},
+	}, listing.Entries)
+	assert.False(t, listing.Truncated)
+}
+
+func TestFindSitemapURLs_Sources(t *testing.T) {
+	tests := []struct {
+		name       string
+		withRobots bool
+		siteURL    string
+		limit      int
+		entries    int
+		truncated  bool
+	}{
+		{"sitemap URL", true, "https://example.com/pages.xml", 10, 2, false},
+		{"fallback to /sitemap.xml", false, "https://example.com/", 10, 1, false},
+		{"limit", true, "https://example.com/", 2, 2, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			analyser := NewAnalyzer(newSitemapClient(t, testCase.withRobots)).(*DefaultAnalyzer)
+
+			listing, err := analyser.FindSitemapURLs(context.Background(), testCase.siteURL, testCase.limit)
+
+			require.NoError(t, err)
+			assert.Len(t, listing.Entries, testCase.entries)
+			assert.Equal(t, testCase.truncated, listing.Truncated)
+		})
+	}
+}
+
+func TestFindSitemapURLs_RelativeSitemapInRobots(t *testing.T) {
+	client := newSitemapClient(t, false)
+	serve := client.DoFunc
+	client.DoFunc = func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/robots.txt" {
+			return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("Sitemap: /pages.xml\n"))}, nil
+		}
+		return serve(req)
+	}
+	analyser := NewAnalyzer(client).(*DefaultAnalyzer)
+
+	listing, err := analyser.FindSitemapURLs(context.Background(), "https://example.com/", 10)
+
+	require.NoError(t, err)
+	require.Len(t, listing.Sitemaps, 1)
+	assert.Equal(t, "https://example.com/pages.xml", listing.Sitemaps[0].URL)
+	assert.Len(t, listing.Entries, 2)
+}
+
+func TestFindSitemapURLs_NoSitemap(t *testing.T) {
+	analyser := NewAnalyzer(newSitemapClient(t, true)).(*DefaultAnalyzer)
+
+	_, err := analyser.FindSitemapURLs(context.Background(), "https://example.com/missing.xml", 10)
+
+	assert.True(t, errors.Is(err, ErrNoSitemap))
+	assert.Contains(t, err.Error(), "https://example.com/missing.xml")
+}
+
+func TestFindSitemapURLs_Unreachable(t *testing.T) {
+	unreachable := errors.New("connection refused")
+	client := &mockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return nil, unreachable
+		},
+	}
+	analyser := NewAnalyzer(client).(*DefaultAnalyzer)
+
+	_, err := analyser.FindSitemapURLs(context.Background(), "https://example.com/sitemap.xml", 10)
+
+	assert.ErrorIs(t, err, unreachable)
+	assert.False(t, errors.Is(err, ErrNoSitemap))
+	assert.Contains(t, err.Error(), "https://example.com/sitemap.xml")
+}
+
+func TestBatchRunner_RunSitemap(t *testing.T) {
+	analyser := NewAnalyzer(newSitemapClient(t, true)).(*DefaultAnalyzer)
+	runner := NewBatchRunner(analyser, 2)
+
+	report, err := runner.RunSitemap(context.Background(), analyser, "https://example.com/", 10)
+
+	require.NoError(t, err)
+	assert.Len(t, report.Sitemaps, 4)
+	assert.Equal(t, 3, report.Batch.Totals.URLs)
+	assert.Equal(t, 2, report.Batch.Totals.Succeeded)
+	assert.Equal(t, []SitemapIssue{
+		{URL: "https://example.com/moved", Kind: SitemapRedirect, StatusCode: http.StatusMovedPermanently, RedirectedTo: "https://example.com/new"},
+		{URL: "https://example.com/gone", Kind: SitemapNon2xx, StatusCode: http.StatusNotFound},
+	}, report.Issues)
+}
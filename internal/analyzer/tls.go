@@ -0,0 +1,150 @@
+package analyzer
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+)
+
+// certExpiryWarningDays is how close to expiry a certificate must be to be flagged.
+const certExpiryWarningDays = 30
+
+// TLSInfo describes the TLS connection and certificate chain an HTTPS page was served with.
+type TLSInfo struct {
+	// Version and CipherSuite are only known when the handshake completed.
+	Version     string `json:"version,omitempty"`
+	CipherSuite string `json:"cipher_suite,omitempty"`
+	// Verified is set when the chain was trusted and matched the host name.
+	Verified bool              `json:"verified"`
+	Chain    []CertificateInfo `json:"chain"`
+	Warnings []string          `json:"warnings,omitempty"`
+}
+
+// CertificateInfo describes one certificate of the chain, starting with the server's own.
+type CertificateInfo struct {
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	DNSNames     []string  `json:"dns_names,omitempty"`
+	IPAddresses  []string  `json:"ip_addresses,omitempty"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+	DaysToExpiry int       `json:"days_to_expiry"`
+	SelfSigned   bool      `json:"self_signed"`
+}
+
+// Leaf returns the server's own certificate, or nil if none was received.
+func (info *TLSInfo) Leaf() *CertificateInfo {
+	if info == nil || len(info.Chain) == 0 {
+		return nil
+	}
+	return &info.Chain[0]
+}
+
+// CertificateError is returned by FetchHTML when the server's certificate could not be verified.
+// TLS holds what could be learned about the rejected chain.
+type CertificateError struct {
+	Err error
+	TLS *TLSInfo
+}
+
+func (e *CertificateError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CertificateError) Unwrap() error {
+	return e.Err
+}
+
+// inspectConnection describes a completed TLS handshake with the server named host.
+func inspectConnection(state *tls.ConnectionState, host string, now time.Time) *TLSInfo {
+	info := describeChain(state.PeerCertificates, host, now)
+	info.Version = tls.VersionName(state.Version)
+	info.CipherSuite = tls.CipherSuiteName(state.CipherSuite)
+	info.Verified = len(state.VerifiedChains) > 0
+	if state.Version < tls.VersionTLS12 {
+		info.Warnings = append(info.Warnings, info.Version+" is deprecated; use TLS 1.2 or later")
+	}
+	return info
+}
+
+// certificateError wraps err in a CertificateError if the request failed because the certificate was rejected.
+func certificateError(err error, now time.Time) error {
+	var verificationErr *tls.CertificateVerificationError
+	if !errors.As(err, &verificationErr) {
+		return err
+	}
+
+	host := ""
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if parsed, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+			host = parsed.Hostname()
+		}
+	}
+
+	info := describeChain(verificationErr.UnverifiedCertificates, host, now)
+	var authorityErr x509.UnknownAuthorityError
+	if errors.As(err, &authorityErr) && !hasWarning(info, "self-signed") {
+		info.Warnings = append(info.Warnings, "issued by an untrusted certificate authority")
+	}
+	return &CertificateError{Err: err, TLS: info}
+}
+
+// describeChain summarizes the certificates and warns about an expired, soon-expiring,
+// not yet valid, self-signed or hostname-mismatched server certificate.
+func describeChain(certs []*x509.Certificate, host string, now time.Time) *TLSInfo {
+	info := &TLSInfo{}
+	for _, cert := range certs {
+		info.Chain = append(info.Chain, describeCertificate(cert, now))
+	}
+	if len(certs) == 0 {
+		return info
+	}
+
+	leaf, leafInfo := certs[0], info.Chain[0]
+	switch {
+	case now.After(leaf.NotAfter):
+		info.Warnings = append(info.Warnings, "expired on "+leaf.NotAfter.Format(time.DateOnly))
+	case now.Before(leaf.NotBefore):
+		info.Warnings = append(info.Warnings, "not valid until "+leaf.NotBefore.Format(time.DateOnly))
+	case leafInfo.DaysToExpiry < certExpiryWarningDays:
+		info.Warnings = append(info.Warnings, fmt.Sprintf("expires in %d days", leafInfo.DaysToExpiry))
+	}
+	if leafInfo.SelfSigned {
+		info.Warnings = append(info.Warnings, "self-signed")
+	}
+	if host != "" && leaf.VerifyHostname(host) != nil {
+		info.Warnings = append(info.Warnings, "does not match host "+host)
+	}
+	return info
+}
+
+func describeCertificate(cert *x509.Certificate, now time.Time) CertificateInfo {
+	info := CertificateInfo{
+		Subject:      cert.Subject.String(),
+		Issuer:       cert.Issuer.String(),
+		DNSNames:     cert.DNSNames,
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+		DaysToExpiry: int(math.Floor(cert.NotAfter.Sub(now).Hours() / 24)),
+		SelfSigned:   bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil,
+	}
+	for _, ip := range cert.IPAddresses {
+		info.IPAddresses = append(info.IPAddresses, ip.String())
+	}
+	return info
+}
+
+func hasWarning(info *TLSInfo, warning string) bool {
+	for _, existing := range info.Warnings {
+		if existing == warning {
+			return true
+		}
+	}
+	return false
+}
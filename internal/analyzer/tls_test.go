@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCertificateServer starts an HTTPS server with a self-signed certificate for the given host names,
+// valid between notBefore and notAfter, and returns it with a client that trusts that certificate.
+func newCertificateServer(t *testing.T, names []string, notBefore, notAfter time.Time) (*httptest.Server, *http.Client) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: names[0]},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, name := range names {
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/html")
+		_, _ = writer.Write([]byte("<title>Secure</title>"))
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	return server, client
+}
+
+func TestAnalyze_TLSVerified(t *testing.T) {
+	now := time.Now()
+	server, client := newCertificateServer(t, []string{"127.0.0.1", "example.test"}, now.Add(-time.Hour), now.Add(365*24*time.Hour))
+
+	report, err := NewAnalyzer(client).Analyze(context.Background(), server.URL)
+
+	require.NoError(t, err)
+	require.NotNil(t, report.TLS)
+	assert.True(t, report.TLS.Verified)
+	assert.Equal(t, "TLS 1.3", report.TLS.Version)
+	assert.NotEmpty(t, report.TLS.CipherSuite)
+	if assert.Len(t, report.TLS.Chain, 1) {
+		leaf := report.TLS.Chain[0]
+		assert.Equal(t, "CN=127.0.0.1", leaf.Subject)
+		assert.Equal(t, leaf.Subject, leaf.Issuer)
+		assert.Equal(t, []string{"example.test"}, leaf.DNSNames)
+		assert.Equal(t, []string{"127.0.0.1"}, leaf.IPAddresses)
+		assert.Equal(t, 364, leaf.DaysToExpiry)
+	}
+	assert.Equal(t, []string{"self-signed"}, report.TLS.Warnings)
+}
+
+func TestAnalyze_TLSWarnings(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name       string
+		names      []string
+		notBefore  time.Time
+		notAfter   time.Time
+		expectFail bool
+		warning    string
+	}{
+		{"expiring soon", []string{"127.0.0.1"}, now.Add(-day), now.Add(10 * day), false, "expires in 9 days"},
+		{"expired", []string{"127.0.0.1"}, now.Add(-30 * day), now.Add(-2 * day), true, "expired on " + now.Add(-2*day).UTC().Format(time.DateOnly)},
+		{"not yet valid", []string{"127.0.0.1"}, now.Add(2 * day), now.Add(30 * day), true, "not valid until " + now.Add(2*day).UTC().Format(time.DateOnly)},
+		{"hostname mismatch", []string{"other.test"}, now.Add(-day), now.Add(90 * day), true, "does not match host 127.0.0.1"},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			server, client := newCertificateServer(t, testCase.names, testCase.notBefore, testCase.notAfter)
+
+			report, err := NewAnalyzer(client).Analyze(context.Background(), server.URL)
+
+			if testCase.expectFail {
+				var certificateErr *CertificateError
+				assert.True(t, errors.As(err, &certificateErr), "expected CertificateError, got %v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+			require.NotNil(t, report.TLS)
+			assert.Equal(t, !testCase.expectFail, report.TLS.Verified)
+			assert.Contains(t, report.TLS.Warnings, testCase.warning)
+		})
+	}
+}
+
+func TestFetchHTML_UntrustedCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/html")
+	}))
+	defer server.Close()
+
+	_, err := NewAnalyzer(&http.Client{}).FetchHTML(context.Background(), server.URL)
+
+	var certificateErr *CertificateError
+	require.True(t, errors.As(err, &certificateErr), "expected CertificateError, got %v", err)
+	var authorityErr x509.UnknownAuthorityError
+	assert.True(t, errors.As(err, &authorityErr))
+	assert.False(t, certificateErr.TLS.Verified)
+	assert.Empty(t, certificateErr.TLS.Version)
+	assert.NotEmpty(t, certificateErr.TLS.Chain)
+	assert.Contains(t, certificateErr.TLS.Warnings, "self-signed")
+}
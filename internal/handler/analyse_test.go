@@ -1,13 +1,16 @@
 package handler
 
 import (
+	"context"
 	"fmt"
+	"html/template"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
 	"github.com/gin-gonic/gin"
@@ -17,8 +20,59 @@ import (
 // mockAnalyzer implements the Analyzer interface with stubbed values
 type mockAnalyzer struct{}
 
-func (m *mockAnalyzer) FetchHTML(url string) (string, error) {
-	return `
+func (m *mockAnalyzer) Analyze(ctx context.Context, url string) (*analyzer.AnalysisReport, error) {
+	return &analyzer.AnalysisReport{
+		URL:       url,
+		FinalURL:  url + "/home",
+		Redirects: analyzer.RedirectChain{Hops: []analyzer.RedirectHop{{URL: url, StatusCode: 301, Location: url + "/home"}}},
+		Response: analyzer.ResponseInfo{
+			StatusCode: 200, Protocol: "HTTP/2.0", ContentLength: -1, ContentEncoding: "gzip",
+			Headers: http.Header{"Content-Type": {"text/html"}},
+		},
+		Page:        analyzer.PageInfo{ContentType: "text/html", Encoding: "utf-8", Size: 1024},
+		HTMLVersion: "HTML 5",
+		Title:       "Mock Title",
+		Headings:    map[string]int{"h1": 1},
+		Links:       analyzer.LinkSummary{Internal: 1, External: 1, Broken: 1},
+		LinkResults: []analyzer.LinkResult{
+			{Href: "/internal", URL: "http://example.com/internal", Tag: "a", Text: "Internal Link", Internal: true, StatusCode: 200},
+			{Href: "http://broken-link.com", URL: "http://broken-link.com", Tag: "a", Text: "Broken Link", Broken: true, StatusCode: 404, Outcome: analyzer.OutcomeClientError, Attempts: 1},
+			{Href: "http://slow.example.com", URL: "http://slow.example.com", Tag: "a", Text: "Slow Link", Outcome: analyzer.OutcomeTimeout, Attempts: 3},
+			{Href: "/cached", URL: "http://example.com/cached", Tag: "a", Text: "Cached Link", Internal: true, StatusCode: 200, Outcome: analyzer.OutcomeOK, Cached: true},
+		},
+		LinkCache:    analyzer.CacheStats{Hits: 1, Misses: 3, Duplicates: 2},
+		HasLoginForm: true,
+		Security: analyzer.SecurityReport{
+			Checks:           []analyzer.SecurityCheck{{Name: analyzer.CheckHSTS, Grade: analyzer.GradeFail, Message: "No HSTS"}},
+			UnprotectedLogin: true,
+		},
+		Resources: analyzer.ResourceReport{
+			Checked: true,
+			Counts:  map[analyzer.ResourceType]analyzer.ResourceCount{analyzer.ResourceImage: {Total: 2, Broken: 1}},
+			Results: []analyzer.LinkResult{{Href: "/logo.png", URL: "http://example.com/logo.png", Tag: "img", Type: analyzer.ResourceImage, StatusCode: 404, Broken: true}},
+		},
+		MixedContent: analyzer.MixedContentReport{
+			Checked: true,
+			Active:  []analyzer.MixedResource{{Tag: "script", Attribute: "src", URL: "http://cdn.example.com/app.js"}},
+		},
+		SEO: analyzer.SEOReport{
+			Canonical:  "http://example.com/canonical",
+			Alternates: []analyzer.Alternate{{HrefLang: "de", URL: "http://example.com/de"}},
+			Checks:     []analyzer.SEOCheck{{Name: analyzer.CheckIndexing, Grade: analyzer.GradeFail, Message: "The robots meta tag asks search engines not to index the page."}},
+		},
+		Robots: &analyzer.RobotsReport{
+			URL:        "http://example.com/robots.txt",
+			StatusCode: 200,
+			Crawlers: []analyzer.CrawlerAccess{
+				{UserAgent: "gogeturl", Allowed: true},
+				{UserAgent: "GPTBot", Allowed: false, CrawlDelay: 5 * time.Second},
+			},
+		},
+	}, nil
+}
+
+func (m *mockAnalyzer) FetchHTML(ctx context.Context, url string) (*analyzer.Page, error) {
+	return &analyzer.Page{ContentType: "text/html", Body: `
 		<!DOCTYPE html>
 		<html>
 			<head><title>Mock Title</title></head>
@@ -31,7 +85,7 @@ func (m *mockAnalyzer) FetchHTML(url string) (string, error) {
 				<h2>Login Form Detection</h2>
 			</body>
 		</html>
-	`, nil
+	`}, nil
 }
 
 func (m *mockAnalyzer) DetectHTMLVersion(body string) string {
@@ -50,7 +104,7 @@ func (m *mockAnalyzer) DetectLoginForm(body string) bool {
 	return true
 }
 
-func (m *mockAnalyzer) AnalyzeLinks(body, baseURL string) (int, int, int, error) {
+func (m *mockAnalyzer) AnalyzeLinks(ctx context.Context, body, baseURL string) (int, int, int, error) {
 	return 1, 1, 0, nil
 }
 
@@ -58,8 +112,12 @@ type failingMockAnalyzer struct {
 	mockAnalyzer
 }
 
-func (m *failingMockAnalyzer) FetchHTML(url string) (string, error) {
-	return "", fmt.Errorf("mock fetch error")
+func (m *failingMockAnalyzer) Analyze(ctx context.Context, url string) (*analyzer.AnalysisReport, error) {
+	return &analyzer.AnalysisReport{URL: url}, fmt.Errorf("mock fetch error")
+}
+
+func (m *failingMockAnalyzer) FetchHTML(ctx context.Context, url string) (*analyzer.Page, error) {
+	return nil, fmt.Errorf("mock fetch error")
 }
 
 func setUp(a analyzer.Analyzer) *gin.Engine {
@@ -93,9 +151,31 @@ func TestAnalyzeHandler(t *testing.T) {
 	assert.Contains(t, body, "Analyzing: http://example.com")
 	assert.Contains(t, body, "h1")
 	assert.Contains(t, body, "Internal Links")
+	assert.Contains(t, body, "timeout (3 attempts)")
+	assert.Contains(t, body, "ok (cached)")
+	assert.Contains(t, body, "Link Cache: 1 reused, 3 checked, 2 duplicates")
+	assert.Contains(t, body, "http://example.com/robots.txt")
+	assert.Contains(t, body, "http://example.com/canonical")
+	assert.Contains(t, body, "The robots meta tag asks search engines not to index the page.")
+	assert.Contains(t, body, "<td>GPTBot</td>")
+	assert.Contains(t, body, "Disallowed")
+	assert.Contains(t, body, "5s")
+	assert.Contains(t, body, "<td>client_error</td>")
 	assert.Contains(t, body, "External Links")
 	assert.Contains(t, body, "Broken Links")
 	assert.Contains(t, body, "Login Form Detection")
+	assert.Contains(t, body, "Character Encoding: utf-8")
+	assert.Contains(t, body, "Final URL: http://example.com/home")
+	assert.Contains(t, body, "Protocol: HTTP/2.0")
+	assert.Contains(t, body, `<td class="grade-fail">fail</td>`)
+	assert.Contains(t, body, "This login page is served without HSTS")
+	assert.Contains(t, body, "Active (scripts, frames, stylesheets, forms): 1")
+	assert.Contains(t, body, "http://cdn.example.com/app.js")
+	assert.Contains(t, body, `<span class="heading-level">image</span>: 2 (1 broken)`)
+	assert.Contains(t, body, "Content Length: not declared")
+	assert.Contains(t, body, `<tr class="broken-link">`)
+	assert.Contains(t, body, "http://broken-link.com")
+	assert.Contains(t, body, "404")
 }
 
 func TestAnalyzeHandler_EmptyURL(t *testing.T) {
@@ -142,3 +222,28 @@ func TestAnalyzeHandler_FetchFailure(t *testing.T) {
 	assert.Equal(t, http.StatusOK, recorder.Code)
 	assert.Contains(t, recorder.Body.String(), "Unable to fetch the provided URL")
 }
+
+func TestIndexTemplate_RobotsSummary(t *testing.T) {
+	path, _ := filepath.Abs("../../cmd/templates/*")
+	templates := template.Must(template.ParseGlob(path))
+
+	tests := []struct {
+		name     string
+		robots   analyzer.RobotsReport
+		expected string
+	}{
+		{"found", analyzer.RobotsReport{StatusCode: 203}, "applies to this page as follows."},
+		{"missing", analyzer.RobotsReport{StatusCode: 404}, "was not found (404), so every crawler is allowed."},
+		{"server error", analyzer.RobotsReport{StatusCode: 503, Error: "robots.txt responded with 503"}, "so every crawler is treated as disallowed."},
+		{"unreachable", analyzer.RobotsReport{Error: "connection refused"}, "could not be fetched (connection refused), so every crawler is treated as allowed."},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			report := &analyzer.AnalysisReport{URL: "http://example.com", Robots: &testCase.robots}
+			var body strings.Builder
+			assert.NoError(t, templates.ExecuteTemplate(&body, "index.html", gin.H{"Report": report}))
+			assert.Contains(t, body.String(), testCase.expected)
+		})
+	}
+}
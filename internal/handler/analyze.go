@@ -10,7 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func AnalyzeHandler(analyzer analyzer.Analyzer) gin.HandlerFunc {
+func AnalyzeHandler(analyser analyzer.Analyzer) gin.HandlerFunc {
 	return func(context *gin.Context) {
 		url := context.PostForm("url")
 
@@ -32,7 +32,7 @@ func AnalyzeHandler(analyzer analyzer.Analyzer) gin.HandlerFunc {
 
 		slog.Info("Received URL for analysis", "url", url)
 
-		body, err := analyzer.FetchHTML(url)
+		report, err := analyser.Analyze(context.Request.Context(), url)
 		if err != nil {
 			slog.Error("Failed to fetch HTML", "error", err)
 			context.HTML(http.StatusOK, "index.html", gin.H{
@@ -41,25 +41,13 @@ func AnalyzeHandler(analyzer analyzer.Analyzer) gin.HandlerFunc {
 			return
 		}
 
-		htmlVersion := analyzer.DetectHTMLVersion(body)
-		title := analyzer.ExtractTitle(body)
-		headings := analyzer.CountHeadings(body)
-		hasLoginForm := analyzer.DetectLoginForm(body)
-
-		internal, external, broken, err := analyzer.AnalyzeLinks(body, url)
-		if err != nil {
-			slog.Warn("Link analysis failed", "error", err)
+		if linkErr, ok := report.Errors[analyzer.SectionLinks]; ok {
+			slog.Warn("Link analysis failed", "error", linkErr)
 		}
 
 		context.HTML(http.StatusOK, "index.html", gin.H{
-			"Message":       fmt.Sprintf("Analyzing: %s", url),
-			"HTMLVersion":   htmlVersion,
-			"TitleTag":      title,
-			"Headings":      headings,
-			"InternalLinks": internal,
-			"ExternalLinks": external,
-			"BrokenLinks":   broken,
-			"HasLoginForm":  hasLoginForm,
+			"Message": fmt.Sprintf("Analyzing: %s", url),
+			"Report":  report,
 		})
 	}
 }
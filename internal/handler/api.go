@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gayansanjeewa/gogeturl/internal/netguard"
+	"github.com/gayansanjeewa/gogeturl/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Machine-readable error codes returned in APIError.Code.
+const (
+	ErrCodeMissingURL       = "missing_url"
+	ErrCodeInvalidURL       = "invalid_url"
+	ErrCodeInvalidRequest   = "invalid_request"
+	ErrCodeUpstreamStatus   = "upstream_status"
+	ErrCodeUpstreamTimeout  = "upstream_timeout"
+	ErrCodeDNSFailure       = "dns_failure"
+	ErrCodeCancelled        = "cancelled"
+	ErrCodeFetchFailed      = "fetch_failed"
+	ErrCodeInternal         = "internal_error"
+	ErrCodeBlockedAddress   = "blocked_address"
+	ErrCodeUnsupportedType  = "unsupported_content_type"
+	ErrCodeRedirectLoop     = "redirect_loop"
+	ErrCodeTooManyRedirects = "too_many_redirects"
+	ErrCodeTLSError         = "tls_error"
+)
+
+// APIError is the error object returned by every JSON endpoint.
+type APIError struct {
+	Code           string `json:"code"`
+	Message        string `json:"message"`
+	UpstreamStatus int    `json:"upstream_status,omitempty"`
+}
+
+// analyzeRequest is the payload accepted by the JSON analyze endpoints, from a JSON body, form, or query string.
+type analyzeRequest struct {
+	URL string `json:"url" form:"url"`
+}
+
+// AnalyzeAPIHandler serves the versioned JSON API. It accepts the URL as a JSON body or form field
+// on POST, or as the "url" query parameter on GET, and responds with the full AnalysisReport.
+func AnalyzeAPIHandler(analyser analyzer.Analyzer) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		url, ok := bindURL(context)
+		if !ok {
+			return
+		}
+
+		slog.Info("Received URL for API analysis", "url", url)
+
+		report, err := analyser.Analyze(context.Request.Context(), url)
+		if err != nil {
+			slog.Error("Failed to fetch HTML", "error", err)
+			status, apiErr := fetchErrorToAPIError(err)
+			abortWithAPIError(context, status, apiErr)
+			return
+		}
+
+		context.JSON(http.StatusOK, report)
+	}
+}
+
+// bindURL reads and validates the target URL of an API request.
+// On failure it writes the error response and returns false.
+func bindURL(context *gin.Context) (string, bool) {
+	var request analyzeRequest
+	if err := context.ShouldBind(&request); err != nil {
+		slog.Warn("Invalid API request", "error", err)
+		abortWithAPIError(context, http.StatusBadRequest, APIError{Code: ErrCodeInvalidRequest, Message: err.Error()})
+		return "", false
+	}
+
+	if request.URL == "" {
+		abortWithAPIError(context, http.StatusBadRequest, APIError{Code: ErrCodeMissingURL, Message: "Please provide a URL."})
+		return "", false
+	}
+
+	if err := utils.ValidateURL(request.URL); err != nil {
+		slog.Warn("Invalid URL", "error", err)
+		abortWithAPIError(context, http.StatusBadRequest, APIError{Code: ErrCodeInvalidURL, Message: err.Error()})
+		return "", false
+	}
+
+	return request.URL, true
+}
+
+// fetchErrorToAPIError maps a failure to fetch the target page to an HTTP status and error object.
+func fetchErrorToAPIError(err error) (int, APIError) {
+	apiErr := APIError{Code: ErrCodeFetchFailed, Message: "Unable to fetch the provided URL. Reason: " + err.Error()}
+
+	var statusErr *analyzer.StatusError
+	var contentTypeErr *analyzer.ContentTypeError
+	var blockedErr *netguard.BlockedError
+	var certificateErr *analyzer.CertificateError
+	var dnsErr *net.DNSError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &contentTypeErr):
+		apiErr.Code = ErrCodeUnsupportedType
+		return http.StatusUnsupportedMediaType, apiErr
+	case errors.As(err, &blockedErr):
+		apiErr.Code = ErrCodeBlockedAddress
+		return http.StatusForbidden, apiErr
+	case errors.As(err, &certificateErr):
+		apiErr.Code = ErrCodeTLSError
+		return http.StatusBadGateway, apiErr
+	case errors.Is(err, analyzer.ErrRedirectLoop):
+		apiErr.Code = ErrCodeRedirectLoop
+		return http.StatusBadGateway, apiErr
+	case errors.Is(err, analyzer.ErrTooManyRedirects):
+		apiErr.Code = ErrCodeTooManyRedirects
+		return http.StatusBadGateway, apiErr
+	case errors.As(err, &statusErr):
+		apiErr.Code = ErrCodeUpstreamStatus
+		apiErr.UpstreamStatus = statusErr.StatusCode
+		return http.StatusBadGateway, apiErr
+	case errors.Is(err, context.Canceled):
+		apiErr.Code = ErrCodeCancelled
+		return http.StatusServiceUnavailable, apiErr
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		apiErr.Code = ErrCodeUpstreamTimeout
+		return http.StatusGatewayTimeout, apiErr
+	case errors.As(err, &dnsErr):
+		apiErr.Code = ErrCodeDNSFailure
+		return http.StatusBadGateway, apiErr
+	default:
+		return http.StatusBadGateway, apiErr
+	}
+}
+
+// requestErrorMessage words an error about the request itself for the user who sent it.
+func requestErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, errMissingURL):
+		return "Please provide a URL."
+	case errors.Is(err, errMissingURLs):
+		return "Please provide at least one URL."
+	case errors.Is(err, errTooManyURLs):
+		return fmt.Sprintf("A batch may contain at most %d URLs.", maxBatchURLs)
+	case errors.Is(err, errCrawlLimits):
+		return fmt.Sprintf("Please keep max_depth between 0 and %d and max_pages between 1 and %d.", maxCrawlDepth, maxCrawlPages)
+	case errors.Is(err, errSitemapLimit):
+		return fmt.Sprintf("Please keep max_urls between 1 and %d.", maxBatchURLs)
+	default:
+		return err.Error()
+	}
+}
+
+func abortWithAPIError(context *gin.Context, status int, apiErr APIError) {
+	context.AbortWithStatusJSON(status, gin.H{"error": apiErr})
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gayansanjeewa/gogeturl/internal/netguard"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type statusFailingMockAnalyzer struct {
+	mockAnalyzer
+}
+
+func (m *statusFailingMockAnalyzer) Analyze(ctx context.Context, url string) (*analyzer.AnalysisReport, error) {
+	return &analyzer.AnalysisReport{URL: url}, &analyzer.StatusError{StatusCode: 404, Status: "404 Not Found"}
+}
+
+func setUpAPI(a analyzer.Analyzer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	api := router.Group("/api/v1")
+	api.GET("/analyze", AnalyzeAPIHandler(a))
+	api.POST("/analyze", AnalyzeAPIHandler(a))
+	return router
+}
+
+func decodeAPIError(t *testing.T, recorder *httptest.ResponseRecorder) APIError {
+	var payload struct {
+		Error APIError `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &payload))
+	return payload.Error
+}
+
+func TestAnalyzeAPIHandler_GET(t *testing.T) {
+	router := setUpAPI(&mockAnalyzer{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/analyze?url=http://example.com", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var report analyzer.AnalysisReport
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	assert.Equal(t, "http://example.com", report.URL)
+	assert.Equal(t, "Mock Title", report.Title)
+	assert.Equal(t, 1, report.Links.Internal)
+	assert.True(t, report.HasLoginForm)
+}
+
+func TestAnalyzeAPIHandler_POSTJSON(t *testing.T) {
+	router := setUpAPI(&mockAnalyzer{})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/analyze", strings.NewReader(`{"url":"http://example.com"}`))
+	req.Header.Add("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"title":"Mock Title"`)
+}
+
+func TestAnalyzeAPIHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		analyzer       analyzer.Analyzer
+		target         string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"missing URL", &mockAnalyzer{}, "/api/v1/analyze", http.StatusBadRequest, ErrCodeMissingURL},
+		{"invalid URL", &mockAnalyzer{}, "/api/v1/analyze?url=invalid-url", http.StatusBadRequest, ErrCodeInvalidURL},
+		{"fetch failure", &failingMockAnalyzer{}, "/api/v1/analyze?url=http://example.com", http.StatusBadGateway, ErrCodeFetchFailed},
+		{"upstream status", &statusFailingMockAnalyzer{}, "/api/v1/analyze?url=http://example.com", http.StatusBadGateway, ErrCodeUpstreamStatus},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			router := setUpAPI(testCase.analyzer)
+
+			req := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, testCase.expectedStatus, recorder.Code)
+			assert.Equal(t, testCase.expectedCode, decodeAPIError(t, recorder).Code)
+		})
+	}
+}
+
+func TestFetchErrorToAPIError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"upstream status", &analyzer.StatusError{StatusCode: 500, Status: "500 Internal Server Error"}, http.StatusBadGateway, ErrCodeUpstreamStatus},
+		{"content type", &analyzer.ContentTypeError{ContentType: "video/mp4"}, http.StatusUnsupportedMediaType, ErrCodeUnsupportedType},
+		{"deadline", context.DeadlineExceeded, http.StatusGatewayTimeout, ErrCodeUpstreamTimeout},
+		{"cancelled", context.Canceled, http.StatusServiceUnavailable, ErrCodeCancelled},
+		{"dns", &net.DNSError{Err: "no such host", Name: "nope.invalid"}, http.StatusBadGateway, ErrCodeDNSFailure},
+		{"blocked", &url.Error{Op: "Get", URL: "http://127.0.0.1", Err: &net.OpError{Op: "dial", Err: &netguard.BlockedError{Address: "127.0.0.1:80"}}}, http.StatusForbidden, ErrCodeBlockedAddress},
+		{"redirect loop", &analyzer.RedirectError{Err: analyzer.ErrRedirectLoop}, http.StatusBadGateway, ErrCodeRedirectLoop},
+		{"too many redirects", &analyzer.RedirectError{Err: analyzer.ErrTooManyRedirects}, http.StatusBadGateway, ErrCodeTooManyRedirects},
+		{"certificate", &analyzer.CertificateError{Err: errors.New("x509: certificate has expired")}, http.StatusBadGateway, ErrCodeTLSError},
+		{"other", errors.New("boom"), http.StatusBadGateway, ErrCodeFetchFailed},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			status, apiErr := fetchErrorToAPIError(testCase.err)
+			assert.Equal(t, testCase.expectedStatus, status)
+			assert.Equal(t, testCase.expectedCode, apiErr.Code)
+		})
+	}
+}
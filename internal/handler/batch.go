@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gayansanjeewa/gogeturl/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ErrCodeMissingURLs  = "missing_urls"
+	ErrCodeTooManyURLs  = "too_many_urls"
+	maxBatchURLs        = 500
+	maxBatchUploadBytes = 1 << 20
+)
+
+var (
+	errMissingURLs = errors.New("no URLs given")
+	errTooManyURLs = fmt.Errorf("more than %d URLs given", maxBatchURLs)
+)
+
+// BatchHandler analyzes the URLs submitted through the batch form, from the "urls" textarea
+// and/or an uploaded "file" with one URL per line, and renders the combined report.
+func BatchHandler(runner *analyzer.BatchRunner) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		urls, err := readBatchURLs(context)
+		if err != nil {
+			slog.Warn("Invalid batch submission", "error", err)
+			context.HTML(http.StatusBadRequest, "batch.html", gin.H{
+				"Error": requestErrorMessage(err),
+			})
+			return
+		}
+
+		slog.Info("Received URLs for batch analysis", "count", len(urls))
+
+		report := runner.Run(context.Request.Context(), urls)
+		context.HTML(http.StatusOK, "batch.html", gin.H{
+			"Message": fmt.Sprintf("Analyzed %d URLs", len(urls)),
+			"Report":  report,
+		})
+	}
+}
+
+// BatchAPIHandler analyzes the URLs given as a JSON array, a JSON object with a "urls" array,
+// a plain-text body or an uploaded "file", and responds with the combined BatchReport.
+func BatchAPIHandler(runner *analyzer.BatchRunner) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		urls, err := readBatchURLs(context)
+		if err != nil {
+			slog.Warn("Invalid batch request", "error", err)
+			code := ErrCodeInvalidRequest
+			switch {
+			case errors.Is(err, errMissingURLs):
+				code = ErrCodeMissingURLs
+			case errors.Is(err, errTooManyURLs):
+				code = ErrCodeTooManyURLs
+			}
+			abortWithAPIError(context, http.StatusBadRequest, APIError{Code: code, Message: requestErrorMessage(err)})
+			return
+		}
+
+		slog.Info("Received URLs for batch API analysis", "count", len(urls))
+
+		context.JSON(http.StatusOK, runner.Run(context.Request.Context(), urls))
+	}
+}
+
+// readBatchURLs collects the submitted URLs from whichever representation the request uses.
+func readBatchURLs(context *gin.Context) ([]string, error) {
+	var urls []string
+	var err error
+
+	switch context.ContentType() {
+	case gin.MIMEJSON:
+		urls, err = readBatchJSON(context.Request.Body)
+	case gin.MIMEPOSTForm, gin.MIMEMultipartPOSTForm:
+		urls, err = readBatchForm(context)
+	default:
+		urls, err = utils.ParseURLList(io.LimitReader(context.Request.Body, maxBatchUploadBytes))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(urls) == 0 {
+		return nil, errMissingURLs
+	}
+	if len(urls) > maxBatchURLs {
+		return nil, errTooManyURLs
+	}
+	return urls, nil
+}
+
+// readBatchJSON accepts either ["url", ...] or {"urls": ["url", ...]}.
+func readBatchJSON(body io.Reader) ([]string, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(io.LimitReader(body, maxBatchUploadBytes)).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return utils.UniqueURLs(list), nil
+	}
+
+	var object struct {
+		URLs []string `json:"urls"`
+	}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, errors.New("expected a JSON array of URLs or an object with a \"urls\" array")
+	}
+	return utils.UniqueURLs(object.URLs), nil
+}
+
+// readBatchForm combines the "urls" field with the contents of an optional uploaded "file".
+func readBatchForm(context *gin.Context) ([]string, error) {
+	text := context.PostForm("urls")
+
+	if header, err := context.FormFile("file"); err == nil {
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+
+		contents, err := io.ReadAll(io.LimitReader(file, maxBatchUploadBytes))
+		if err != nil {
+			return nil, err
+		}
+		text += "\n" + string(contents)
+	}
+
+	return utils.ParseURLList(strings.NewReader(text))
+}
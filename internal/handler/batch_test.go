@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setUpBatch(a analyzer.Analyzer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	path, _ := filepath.Abs("../../cmd/templates/*")
+	router.LoadHTMLGlob(path)
+
+	runner := analyzer.NewBatchRunner(a, 2)
+	router.POST("/batch", BatchHandler(runner))
+	router.POST("/api/v1/batch", BatchAPIHandler(runner))
+	return router
+}
+
+func decodeBatchReport(t *testing.T, recorder *httptest.ResponseRecorder) analyzer.BatchReport {
+	var report analyzer.BatchReport
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	return report
+}
+
+func TestBatchAPIHandler_JSON(t *testing.T) {
+	router := setUpBatch(&mockAnalyzer{})
+
+	bodies := []string{
+		`["http://example.com", "http://example.com/search?ids=1,2", "http://example.com"]`,
+		`{"urls": ["http://example.com", " http://example.com/search?ids=1,2 "]}`,
+	}
+	for _, body := range bodies {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/batch", strings.NewReader(body))
+		req.Header.Add("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		report := decodeBatchReport(t, recorder)
+		assert.Len(t, report.Results, 2)
+		assert.Equal(t, "http://example.com/search?ids=1,2", report.Results[1].URL)
+		assert.Equal(t, 2, report.Totals.Succeeded)
+		assert.Equal(t, 2, report.Totals.InternalLinks)
+	}
+}
+
+func TestBatchAPIHandler_FileUpload(t *testing.T) {
+	router := setUpBatch(&mockAnalyzer{})
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "urls.txt")
+	_, _ = part.Write([]byte("http://example.com\nhttp://example.com/pricing\n"))
+	_ = writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/batch", &body)
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 2, decodeBatchReport(t, recorder).Totals.URLs)
+}
+
+func TestBatchAPIHandler_MissingURLs(t *testing.T) {
+	router := setUpBatch(&mockAnalyzer{})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/batch", strings.NewReader(`[]`))
+	req.Header.Add("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	apiErr := decodeAPIError(t, recorder)
+	assert.Equal(t, ErrCodeMissingURLs, apiErr.Code)
+	assert.Equal(t, "Please provide at least one URL.", apiErr.Message)
+}
+
+func TestBatchHandler_Form(t *testing.T) {
+	router := setUpBatch(&mockAnalyzer{})
+
+	form := url.Values{}
+	form.Add("urls", "http://example.com\ninvalid-url")
+	req := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	body := recorder.Body.String()
+	assert.Contains(t, body, "Analyzed 2 URLs")
+	assert.Contains(t, body, "1 succeeded, 1 failed")
+	assert.Contains(t, body, "Mock Title")
+	assert.Contains(t, body, "Invalid URL format")
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gayansanjeewa/gogeturl/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxCrawlDepth = 5
+	maxCrawlPages = 200
+)
+
+var (
+	errMissingURL  = errors.New("no URL given")
+	errCrawlLimits = fmt.Errorf("max_depth must be between 0 and %d and max_pages between 1 and %d", maxCrawlDepth, maxCrawlPages)
+)
+
+// crawlRequest is the payload accepted by the crawl endpoints, from a JSON body or form.
+// Limits that are left out fall back to the analyzer defaults.
+type crawlRequest struct {
+	URL      string `json:"url" form:"url"`
+	MaxDepth *int   `json:"max_depth" form:"max_depth"`
+	MaxPages *int   `json:"max_pages" form:"max_pages"`
+}
+
+// CrawlHandler crawls the site submitted through the crawl form and renders the site report.
+func CrawlHandler(crawler *analyzer.Crawler) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		url, options, _, err := readCrawlRequest(context)
+		if err != nil {
+			slog.Warn("Invalid crawl submission", "error", err)
+			context.HTML(http.StatusBadRequest, "crawl.html", gin.H{
+				"Error": requestErrorMessage(err),
+			})
+			return
+		}
+
+		slog.Info("Received URL for crawl", "url", url, "max_depth", options.MaxDepth, "max_pages", options.MaxPages)
+
+		report := crawler.Crawl(context.Request.Context(), url, options)
+		context.HTML(http.StatusOK, "crawl.html", gin.H{
+			"Message": fmt.Sprintf("Crawled %d pages from %s", report.Totals.Pages, url),
+			"Report":  report,
+		})
+	}
+}
+
+// CrawlAPIHandler crawls the site at the "url" of a JSON body or form, following links up to
+// "max_depth" away and analyzing at most "max_pages" pages, and responds with the SiteReport.
+func CrawlAPIHandler(crawler *analyzer.Crawler) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		url, options, code, err := readCrawlRequest(context)
+		if err != nil {
+			slog.Warn("Invalid crawl request", "error", err)
+			abortWithAPIError(context, http.StatusBadRequest, APIError{Code: code, Message: requestErrorMessage(err)})
+			return
+		}
+
+		slog.Info("Received URL for API crawl", "url", url, "max_depth", options.MaxDepth, "max_pages", options.MaxPages)
+
+		context.JSON(http.StatusOK, crawler.Crawl(context.Request.Context(), url, options))
+	}
+}
+
+// readCrawlRequest reads and validates the start URL and limits of a crawl.
+// On failure it also returns the API error code that describes the problem.
+func readCrawlRequest(context *gin.Context) (string, analyzer.CrawlOptions, string, error) {
+	options := analyzer.CrawlOptions{MaxDepth: analyzer.DefaultCrawlDepth, MaxPages: analyzer.DefaultCrawlPages}
+
+	var request crawlRequest
+	if err := context.ShouldBind(&request); err != nil {
+		return "", options, ErrCodeInvalidRequest, err
+	}
+	if request.URL == "" {
+		return "", options, ErrCodeMissingURL, errMissingURL
+	}
+	if err := utils.ValidateURL(request.URL); err != nil {
+		return "", options, ErrCodeInvalidURL, err
+	}
+
+	if request.MaxDepth != nil {
+		options.MaxDepth = *request.MaxDepth
+	}
+	if request.MaxPages != nil {
+		options.MaxPages = *request.MaxPages
+	}
+	if options.MaxDepth < 0 || options.MaxDepth > maxCrawlDepth || options.MaxPages < 1 || options.MaxPages > maxCrawlPages {
+		return "", options, ErrCodeInvalidRequest, errCrawlLimits
+	}
+	return request.URL, options, "", nil
+}
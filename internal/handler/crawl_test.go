@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setUpCrawl(a analyzer.Analyzer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	path, _ := filepath.Abs("../../cmd/templates/*")
+	router.LoadHTMLGlob(path)
+
+	crawler := analyzer.NewCrawler(a, 2)
+	router.POST("/crawl", CrawlHandler(crawler))
+	router.POST("/api/v1/crawl", CrawlAPIHandler(crawler))
+	return router
+}
+
+func TestCrawlAPIHandler(t *testing.T) {
+	router := setUpCrawl(&mockAnalyzer{})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/crawl", strings.NewReader(`{"url": "http://example.com", "max_depth": 1}`))
+	req.Header.Add("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var report analyzer.SiteReport
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	require.Len(t, report.Pages, 3)
+	assert.Equal(t, "http://example.com/internal", report.Pages[1].URL)
+	assert.Equal(t, "http://example.com", report.Pages[1].Referrer)
+	assert.Equal(t, 3, report.Totals.Pages)
+	assert.Equal(t, []string{"http://example.com", "http://example.com/internal", "http://example.com/cached"}, report.BrokenLinks[0].Referrers)
+}
+
+func TestCrawlAPIHandler_InvalidRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		code    string
+		message string
+	}{
+		{"missing URL", `{}`, ErrCodeMissingURL, "Please provide a URL."},
+		{"invalid URL", `{"url": "invalid-url"}`, ErrCodeInvalidURL, "Invalid URL format"},
+		{"too deep", `{"url": "http://example.com", "max_depth": 6}`, ErrCodeInvalidRequest, "Please keep max_depth between 0 and 5 and max_pages between 1 and 200."},
+		{"too many pages", `{"url": "http://example.com", "max_pages": 1000}`, ErrCodeInvalidRequest, "Please keep max_depth between 0 and 5 and max_pages between 1 and 200."},
+		{"malformed", `{"url": `, ErrCodeInvalidRequest, "unexpected EOF"},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			router := setUpCrawl(&mockAnalyzer{})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/crawl", strings.NewReader(testCase.body))
+			req.Header.Add("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			apiErr := decodeAPIError(t, recorder)
+			assert.Equal(t, testCase.code, apiErr.Code)
+			assert.Equal(t, testCase.message, apiErr.Message)
+		})
+	}
+}
+
+func TestCrawlHandler_Form(t *testing.T) {
+	router := setUpCrawl(&mockAnalyzer{})
+
+	form := url.Values{}
+	form.Add("url", "http://example.com")
+	form.Add("max_depth", "0")
+	req := httptest.NewRequest(http.MethodPost, "/crawl", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	body := recorder.Body.String()
+	assert.Contains(t, body, "Crawled 1 pages from http://example.com")
+	assert.Contains(t, body, "Mock Title")
+	assert.Contains(t, body, "http://broken-link.com")
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gayansanjeewa/gogeturl/internal/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ErrCodeQueueFull   = "queue_full"
+	ErrCodeJobNotFound = "job_not_found"
+)
+
+// SubmitJobHandler queues a URL for asynchronous analysis and responds with 202 and the new job,
+// whose ID can be polled through JobStatusHandler.
+func SubmitJobHandler(manager *jobs.Manager) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		url, ok := bindURL(context)
+		if !ok {
+			return
+		}
+
+		job, err := manager.Submit(url)
+		if errors.Is(err, jobs.ErrQueueFull) {
+			abortWithAPIError(context, http.StatusServiceUnavailable, APIError{Code: ErrCodeQueueFull, Message: err.Error()})
+			return
+		}
+		if err != nil {
+			slog.Error("Failed to submit job", "error", err)
+			abortWithAPIError(context, http.StatusInternalServerError, APIError{Code: ErrCodeInternal, Message: err.Error()})
+			return
+		}
+
+		context.Header("Location", context.Request.URL.Path+"/"+job.ID)
+		context.JSON(http.StatusAccepted, job)
+	}
+}
+
+// JobStatusHandler returns the status, progress and, once finished, the report of a job.
+func JobStatusHandler(manager *jobs.Manager) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		job, ok := manager.Get(context.Param("id"))
+		if !ok {
+			abortWithAPIError(context, http.StatusNotFound, APIError{Code: ErrCodeJobNotFound, Message: "No job with this ID."})
+			return
+		}
+
+		context.JSON(http.StatusOK, job)
+	}
+}
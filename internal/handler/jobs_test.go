@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gayansanjeewa/gogeturl/internal/jobs"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setUpJobs(a analyzer.Analyzer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	manager := jobs.NewManager(a, 1, 10)
+	manager.Start(context.Background())
+
+	api := router.Group("/api/v1")
+	api.POST("/jobs", SubmitJobHandler(manager))
+	api.GET("/jobs/:id", JobStatusHandler(manager))
+	return router
+}
+
+func TestJobHandlers_SubmitAndPoll(t *testing.T) {
+	router := setUpJobs(&mockAnalyzer{})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs", strings.NewReader(`{"url":"http://example.com"}`))
+	req.Header.Add("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+	var submitted jobs.Job
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &submitted))
+	assert.NotEmpty(t, submitted.ID)
+	assert.Equal(t, "/api/v1/jobs/"+submitted.ID, recorder.Header().Get("Location"))
+
+	var polled jobs.Job
+	assert.Eventually(t, func() bool {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+submitted.ID, nil))
+		_ = json.Unmarshal(recorder.Body.Bytes(), &polled)
+		return polled.Status == jobs.StatusDone
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, "Mock Title", polled.Report.Title)
+}
+
+func TestJobHandlers_InvalidURL(t *testing.T) {
+	router := setUpJobs(&mockAnalyzer{})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs", strings.NewReader(`{"url":"invalid-url"}`))
+	req.Header.Add("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, ErrCodeInvalidURL, decodeAPIError(t, recorder).Code)
+}
+
+func TestJobHandlers_NotFound(t *testing.T) {
+	router := setUpJobs(&mockAnalyzer{})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/jobs/missing", nil))
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, ErrCodeJobNotFound, decodeAPIError(t, recorder).Code)
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gayansanjeewa/gogeturl/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const ErrCodeNoSitemap = "no_sitemap"
+
+var errSitemapLimit = fmt.Errorf("max_urls must be between 1 and %d", maxBatchURLs)
+
+// sitemapRequest is the payload accepted by the sitemap endpoints, from a JSON body or form.
+// The URL is either a sitemap or any page of the site; MaxURLs falls back to analyzer.DefaultSitemapURLs.
+type sitemapRequest struct {
+	URL     string `json:"url" form:"url"`
+	MaxURLs *int   `json:"max_urls" form:"max_urls"`
+}
+
+// SitemapHandler analyzes the pages listed in the sitemaps of the site submitted through the
+// sitemap form as one batch and renders the report.
+func SitemapHandler(finder analyzer.SitemapFinder, runner *analyzer.BatchRunner) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		url, limit, _, err := readSitemapRequest(context)
+		if err != nil {
+			slog.Warn("Invalid sitemap submission", "error", err)
+			context.HTML(http.StatusBadRequest, "sitemap.html", gin.H{
+				"Error": requestErrorMessage(err),
+			})
+			return
+		}
+
+		slog.Info("Received URL for sitemap analysis", "url", url, "max_urls", limit)
+
+		report, err := runner.RunSitemap(context.Request.Context(), finder, url, limit)
+		if err != nil {
+			slog.Warn("Failed to read sitemap", "error", err)
+			context.HTML(http.StatusOK, "sitemap.html", gin.H{
+				"Error": "Unable to read the sitemap. Reason: " + err.Error(),
+			})
+			return
+		}
+
+		context.HTML(http.StatusOK, "sitemap.html", gin.H{
+			"Message": fmt.Sprintf("Analyzed %d URLs from the sitemaps of %s", len(report.Entries), url),
+			"Report":  report,
+		})
+	}
+}
+
+// SitemapAPIHandler analyzes the pages listed in the sitemaps of the "url" of a JSON body or form,
+// at most "max_urls" of them, and responds with the SitemapReport.
+func SitemapAPIHandler(finder analyzer.SitemapFinder, runner *analyzer.BatchRunner) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		url, limit, code, err := readSitemapRequest(context)
+		if err != nil {
+			slog.Warn("Invalid sitemap request", "error", err)
+			abortWithAPIError(context, http.StatusBadRequest, APIError{Code: code, Message: requestErrorMessage(err)})
+			return
+		}
+
+		slog.Info("Received URL for sitemap API analysis", "url", url, "max_urls", limit)
+
+		report, err := runner.RunSitemap(context.Request.Context(), finder, url, limit)
+		if errors.Is(err, analyzer.ErrNoSitemap) {
+			abortWithAPIError(context, http.StatusNotFound, APIError{Code: ErrCodeNoSitemap, Message: err.Error()})
+			return
+		}
+		if err != nil {
+			slog.Error("Failed to read sitemap", "error", err)
+			status, apiErr := fetchErrorToAPIError(err)
+			abortWithAPIError(context, status, apiErr)
+			return
+		}
+
+		context.JSON(http.StatusOK, report)
+	}
+}
+
+// readSitemapRequest reads and validates the URL and limit of a sitemap analysis.
+// On failure it also returns the API error code that describes the problem.
+func readSitemapRequest(context *gin.Context) (string, int, string, error) {
+	var request sitemapRequest
+	if err := context.ShouldBind(&request); err != nil {
+		return "", 0, ErrCodeInvalidRequest, err
+	}
+	if request.URL == "" {
+		return "", 0, ErrCodeMissingURL, errMissingURL
+	}
+	if err := utils.ValidateURL(request.URL); err != nil {
+		return "", 0, ErrCodeInvalidURL, err
+	}
+
+	limit := analyzer.DefaultSitemapURLs
+	if request.MaxURLs != nil {
+		limit = *request.MaxURLs
+	}
+	if limit < 1 || limit > maxBatchURLs {
+		return "", 0, ErrCodeInvalidRequest, errSitemapLimit
+	}
+	return request.URL, limit, "", nil
+}
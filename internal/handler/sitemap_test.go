@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gayansanjeewa/gogeturl/internal/netguard"
+	"github.com/gayansanjeewa/gogeturl/internal/sitemap"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockSitemapFinder lists two pages for every site except those on nositemap.example, which has
+// none, and blocked.example, whose sitemap cannot be fetched.
+type mockSitemapFinder struct{}
+
+func (m *mockSitemapFinder) FindSitemapURLs(ctx context.Context, siteURL string, limit int) (*analyzer.SitemapListing, error) {
+	if strings.Contains(siteURL, "nositemap.example") {
+		return nil, analyzer.ErrNoSitemap
+	}
+	if strings.Contains(siteURL, "blocked.example") {
+		return nil, fmt.Errorf("http://blocked.example/sitemap.xml: %w", &netguard.BlockedError{Address: "10.0.0.1:80"})
+	}
+	return &analyzer.SitemapListing{
+		Sitemaps: []analyzer.SitemapFile{{URL: "http://example.com/sitemap.xml", URLs: 2}},
+		Entries:  []sitemap.Entry{{URL: "http://example.com"}, {URL: "http://example.com/about"}},
+	}, nil
+}
+
+func setUpSitemap(a analyzer.Analyzer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	path, _ := filepath.Abs("../../cmd/templates/*")
+	router.LoadHTMLGlob(path)
+
+	runner := analyzer.NewBatchRunner(a, 2)
+	router.POST("/sitemap", SitemapHandler(&mockSitemapFinder{}, runner))
+	router.POST("/api/v1/sitemap", SitemapAPIHandler(&mockSitemapFinder{}, runner))
+	return router
+}
+
+func TestSitemapAPIHandler(t *testing.T) {
+	router := setUpSitemap(&mockAnalyzer{})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sitemap", strings.NewReader(`{"url": "http://example.com"}`))
+	req.Header.Add("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var report analyzer.SitemapReport
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	assert.Len(t, report.Sitemaps, 1)
+	assert.Equal(t, 2, report.Batch.Totals.Succeeded)
+	// Every page of the mock analyzer is reached through a redirect
+	require.Len(t, report.Issues, 2)
+	assert.Equal(t, analyzer.SitemapIssue{URL: "http://example.com", Kind: analyzer.SitemapRedirect, StatusCode: 301, RedirectedTo: "http://example.com/home"}, report.Issues[0])
+}
+
+func TestSitemapAPIHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		code    string
+		message string
+	}{
+		{"missing URL", `{}`, http.StatusBadRequest, ErrCodeMissingURL, "Please provide a URL."},
+		{"invalid URL", `{"url": "invalid-url"}`, http.StatusBadRequest, ErrCodeInvalidURL, "Invalid URL format"},
+		{"too many URLs", `{"url": "http://example.com", "max_urls": 501}`, http.StatusBadRequest, ErrCodeInvalidRequest, "Please keep max_urls between 1 and 500."},
+		{"no sitemap", `{"url": "http://nositemap.example"}`, http.StatusNotFound, ErrCodeNoSitemap, "no sitemap"},
+		{"sitemap unreachable", `{"url": "http://blocked.example"}`, http.StatusForbidden, ErrCodeBlockedAddress, "http://blocked.example/sitemap.xml"},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			router := setUpSitemap(&mockAnalyzer{})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/sitemap", strings.NewReader(testCase.body))
+			req.Header.Add("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, testCase.status, recorder.Code)
+			apiErr := decodeAPIError(t, recorder)
+			assert.Equal(t, testCase.code, apiErr.Code)
+			assert.Contains(t, apiErr.Message, testCase.message)
+		})
+	}
+}
+
+func TestSitemapHandler_Form(t *testing.T) {
+	router := setUpSitemap(&mockAnalyzer{})
+
+	form := url.Values{}
+	form.Add("url", "http://example.com")
+	form.Add("max_urls", "100")
+	req := httptest.NewRequest(http.MethodPost, "/sitemap", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	body := recorder.Body.String()
+	assert.Contains(t, body, "Analyzed 2 URLs from the sitemaps of http://example.com")
+	assert.Contains(t, body, "http://example.com/sitemap.xml")
+	assert.Contains(t, body, "Mock Title")
+	assert.Contains(t, body, "http://example.com/home")
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"log/slog"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gin-gonic/gin"
+)
+
+// Names of the Server-Sent Events emitted by AnalyzeStreamHandler in addition to the analyzer's progress events.
+const (
+	StreamEventReport = "report"
+	StreamEventError  = "error"
+)
+
+// streamBufferSize bounds how many progress events may wait for a slow client before link checks pause.
+const streamBufferSize = 64
+
+// AnalyzeStreamHandler runs an analysis and streams its progress as Server-Sent Events:
+// "fetched", "link_checked" and "broken_link" while links are checked, "done" with the link summary,
+// and finally "report" with the full AnalysisReport, or "error" with an APIError if the page could not be fetched.
+func AnalyzeStreamHandler(analyser analyzer.Analyzer) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		url, ok := bindURL(context)
+		if !ok {
+			return
+		}
+
+		slog.Info("Received URL for streamed analysis", "url", url)
+
+		requestCtx := context.Request.Context()
+		events := make(chan analyzer.ProgressEvent, streamBufferSize)
+		ctx := analyzer.WithProgress(requestCtx, func(event analyzer.ProgressEvent) {
+			// Never block a link worker on a client that has gone away
+			select {
+			case events <- event:
+			case <-requestCtx.Done():
+			}
+		})
+
+		type outcome struct {
+			report *analyzer.AnalysisReport
+			err    error
+		}
+		finished := make(chan outcome, 1)
+		go func() {
+			report, err := analyser.Analyze(ctx, url)
+			finished <- outcome{report, err}
+		}()
+
+		context.Header("Cache-Control", "no-cache")
+		context.Header("X-Accel-Buffering", "no")
+
+		send := func(name string, data any) {
+			context.SSEvent(name, data)
+			context.Writer.Flush()
+		}
+
+		for {
+			select {
+			case event := <-events:
+				send(string(event.Type), event)
+			case result := <-finished:
+				// Flush progress that was queued before the analysis returned
+				for len(events) > 0 {
+					event := <-events
+					send(string(event.Type), event)
+				}
+
+				if result.err != nil {
+					slog.Error("Failed to fetch HTML", "error", result.err)
+					_, apiErr := fetchErrorToAPIError(result.err)
+					send(StreamEventError, apiErr)
+					return
+				}
+
+				send(StreamEventReport, result.report)
+				return
+			case <-requestCtx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// progressMockAnalyzer reports progress for two links, one of them broken, before returning its report.
+type progressMockAnalyzer struct {
+	mockAnalyzer
+}
+
+func (m *progressMockAnalyzer) Analyze(ctx context.Context, url string) (*analyzer.AnalysisReport, error) {
+	broken := analyzer.LinkResult{URL: "http://broken-link.com", Broken: true, StatusCode: 404}
+	summary := analyzer.LinkSummary{Internal: 1, External: 1, Broken: 1}
+
+	analyzer.ReportProgress(ctx, analyzer.ProgressEvent{Type: analyzer.EventFetched, Total: 2})
+	analyzer.ReportProgress(ctx, analyzer.ProgressEvent{Type: analyzer.EventLinkChecked, Checked: 1, Total: 2})
+	analyzer.ReportProgress(ctx, analyzer.ProgressEvent{Type: analyzer.EventLinkChecked, Checked: 2, Total: 2, Link: &broken})
+	analyzer.ReportProgress(ctx, analyzer.ProgressEvent{Type: analyzer.EventBrokenLink, Checked: 2, Total: 2, Link: &broken})
+	analyzer.ReportProgress(ctx, analyzer.ProgressEvent{Type: analyzer.EventDone, Checked: 2, Total: 2, Summary: &summary})
+
+	return m.mockAnalyzer.Analyze(ctx, url)
+}
+
+func setUpStream(a analyzer.Analyzer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/api/v1/analyze/stream", AnalyzeStreamHandler(a))
+	return router
+}
+
+func TestAnalyzeStreamHandler(t *testing.T) {
+	router := setUpStream(&progressMockAnalyzer{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/analyze/stream?url=http://example.com", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Content-Type"), "text/event-stream")
+
+	body := recorder.Body.String()
+	order := []string{"event:fetched", "event:link_checked", "event:broken_link", "event:done", "event:report"}
+	position := 0
+	for _, name := range order {
+		index := strings.Index(body[position:], name)
+		assert.GreaterOrEqual(t, index, 0, "expected %s after position %d", name, position)
+		if index >= 0 {
+			position += index
+		}
+	}
+	assert.Contains(t, body, `"checked":2`)
+	assert.Contains(t, body, "http://broken-link.com")
+	assert.Contains(t, body, `"title":"Mock Title"`)
+}
+
+func TestAnalyzeStreamHandler_FetchFailure(t *testing.T) {
+	router := setUpStream(&failingMockAnalyzer{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/analyze/stream?url=http://example.com", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	body := recorder.Body.String()
+	assert.Contains(t, body, "event:error")
+	assert.Contains(t, body, ErrCodeFetchFailed)
+	assert.NotContains(t, body, "event:report")
+}
+
+func TestAnalyzeStreamHandler_InvalidURL(t *testing.T) {
+	router := setUpStream(&mockAnalyzer{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/analyze/stream?url=invalid-url", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, ErrCodeInvalidURL, decodeAPIError(t, recorder).Code)
+}
@@ -0,0 +1,208 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+)
+
+// Status is the lifecycle state of an analysis job.
+type Status string
+
+const (
+	StatusQueued  Status = "queued"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// ErrQueueFull is returned by Submit when no more jobs can be accepted.
+var ErrQueueFull = errors.New("job queue is full")
+
+const (
+	// jobRetention is how long finished jobs remain available for polling.
+	jobRetention    = time.Hour
+	cleanupInterval = 5 * time.Minute
+)
+
+// Progress reports how many of the page's links have been checked so far.
+type Progress struct {
+	Checked int `json:"checked"`
+	Total   int `json:"total"`
+}
+
+// Job is a snapshot of an asynchronous analysis.
+type Job struct {
+	ID         string                   `json:"id"`
+	URL        string                   `json:"url"`
+	Status     Status                   `json:"status"`
+	Progress   Progress                 `json:"progress"`
+	Report     *analyzer.AnalysisReport `json:"report,omitempty"`
+	Error      string                   `json:"error,omitempty"`
+	CreatedAt  time.Time                `json:"created_at"`
+	StartedAt  *time.Time               `json:"started_at,omitempty"`
+	FinishedAt *time.Time               `json:"finished_at,omitempty"`
+}
+
+// Manager queues analysis jobs and runs them on a fixed pool of workers.
+type Manager struct {
+	analyser analyzer.Analyzer
+	workers  int
+	queue    chan string
+
+	mutex sync.RWMutex
+	jobs  map[string]*Job
+}
+
+// NewManager creates a Manager that runs at most workers analyses at once
+// and holds at most queueSize jobs waiting to start.
+func NewManager(analyser analyzer.Analyzer, workers, queueSize int) *Manager {
+	return &Manager{
+		analyser: analyser,
+		workers:  workers,
+		queue:    make(chan string, queueSize),
+		jobs:     make(map[string]*Job),
+	}
+}
+
+// Start launches the worker pool and the cleanup of expired jobs. Both stop when ctx is cancelled,
+// which also cancels any analysis still running.
+func (manager *Manager) Start(ctx context.Context) {
+	for i := 0; i < manager.workers; i++ {
+		go manager.work(ctx)
+	}
+
+	go func() {
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				manager.removeExpired(now)
+			}
+		}
+	}()
+}
+
+// Submit queues the URL for analysis and returns the new job immediately.
+func (manager *Manager) Submit(targetURL string) (Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	job := &Job{ID: id, URL: targetURL, Status: StatusQueued, CreatedAt: time.Now()}
+
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	select {
+	case manager.queue <- id:
+	default:
+		return Job{}, ErrQueueFull
+	}
+	manager.jobs[id] = job
+
+	slog.Info("Queued analysis job", "id", id, "url", targetURL)
+	return *job, nil
+}
+
+// Get returns a snapshot of the job with the given ID.
+func (manager *Manager) Get(id string) (Job, bool) {
+	manager.mutex.RLock()
+	defer manager.mutex.RUnlock()
+
+	job, ok := manager.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+func (manager *Manager) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-manager.queue:
+			manager.run(ctx, id)
+		}
+	}
+}
+
+// run executes a single job, recording progress and the final report as it goes.
+func (manager *Manager) run(ctx context.Context, id string) {
+	targetURL, ok := manager.update(id, func(job *Job) {
+		now := time.Now()
+		job.Status = StatusRunning
+		job.StartedAt = &now
+	})
+	if !ok {
+		return
+	}
+
+	ctx = analyzer.WithProgress(ctx, func(event analyzer.ProgressEvent) {
+		manager.update(id, func(job *Job) {
+			job.Progress = Progress{Checked: event.Checked, Total: event.Total}
+		})
+	})
+
+	report, err := manager.analyser.Analyze(ctx, targetURL)
+
+	manager.update(id, func(job *Job) {
+		now := time.Now()
+		job.FinishedAt = &now
+		job.Report = report
+		if err != nil {
+			job.Status = StatusFailed
+			job.Error = err.Error()
+			return
+		}
+		job.Status = StatusDone
+	})
+
+	if err != nil {
+		slog.Warn("Analysis job failed", "id", id, "error", err)
+	}
+}
+
+// update applies fn to the job under the manager's lock and returns the job's URL.
+func (manager *Manager) update(id string, fn func(job *Job)) (string, bool) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	job, ok := manager.jobs[id]
+	if !ok {
+		return "", false
+	}
+	fn(job)
+	return job.URL, true
+}
+
+// removeExpired forgets finished jobs older than jobRetention.
+func (manager *Manager) removeExpired(now time.Time) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	for id, job := range manager.jobs {
+		if job.FinishedAt != nil && now.Sub(*job.FinishedAt) > jobRetention {
+			delete(manager.jobs, id)
+		}
+	}
+}
+
+func newJobID() (string, error) {
+	buffer := make([]byte, 16)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
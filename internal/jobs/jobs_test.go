@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gayansanjeewa/gogeturl/internal/analyzer"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAnalyzer reports progress for a fixed number of links and then returns its canned result.
+type stubAnalyzer struct {
+	analyzer.Analyzer
+	release chan struct{}
+	err     error
+}
+
+func (s *stubAnalyzer) Analyze(ctx context.Context, url string) (*analyzer.AnalysisReport, error) {
+	analyzer.ReportProgress(ctx, analyzer.ProgressEvent{Type: analyzer.EventLinkChecked, Checked: 3, Total: 10})
+	if s.release != nil {
+		<-s.release
+	}
+	if s.err != nil {
+		return &analyzer.AnalysisReport{URL: url}, s.err
+	}
+	return &analyzer.AnalysisReport{URL: url, Title: "Stub"}, nil
+}
+
+func waitForStatus(t *testing.T, manager *Manager, id string, status Status) Job {
+	t.Helper()
+	var job Job
+	assert.Eventually(t, func() bool {
+		job, _ = manager.Get(id)
+		return job.Status == status
+	}, time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestManager_RunsJobToCompletion(t *testing.T) {
+	stub := &stubAnalyzer{release: make(chan struct{})}
+	manager := NewManager(stub, 1, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	manager.Start(ctx)
+
+	job, err := manager.Submit("http://example.com")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, job.ID)
+	assert.Equal(t, StatusQueued, job.Status)
+
+	running := waitForStatus(t, manager, job.ID, StatusRunning)
+	assert.Eventually(t, func() bool {
+		running, _ = manager.Get(job.ID)
+		return running.Progress == Progress{Checked: 3, Total: 10}
+	}, time.Second, 5*time.Millisecond)
+	close(stub.release)
+
+	done := waitForStatus(t, manager, job.ID, StatusDone)
+	assert.Equal(t, "Stub", done.Report.Title)
+	assert.NotNil(t, done.StartedAt)
+	assert.NotNil(t, done.FinishedAt)
+}
+
+func TestManager_RecordsFailure(t *testing.T) {
+	manager := NewManager(&stubAnalyzer{err: errors.New("fetch failed")}, 1, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	manager.Start(ctx)
+
+	job, err := manager.Submit("http://example.com")
+	assert.NoError(t, err)
+
+	failed := waitForStatus(t, manager, job.ID, StatusFailed)
+	assert.Equal(t, "fetch failed", failed.Error)
+}
+
+func TestManager_QueueFull(t *testing.T) {
+	// Without Start nothing drains the queue, so the second submission overflows it
+	manager := NewManager(&stubAnalyzer{}, 1, 1)
+
+	_, err := manager.Submit("http://example.com/1")
+	assert.NoError(t, err)
+
+	_, err = manager.Submit("http://example.com/2")
+	assert.ErrorIs(t, err, ErrQueueFull)
+}
+
+func TestManager_RemoveExpired(t *testing.T) {
+	manager := NewManager(&stubAnalyzer{}, 1, 10)
+	job, err := manager.Submit("http://example.com")
+	assert.NoError(t, err)
+
+	finished := time.Now().Add(-2 * jobRetention)
+	manager.update(job.ID, func(job *Job) {
+		job.Status = StatusDone
+		job.FinishedAt = &finished
+	})
+
+	manager.removeExpired(time.Now())
+
+	_, ok := manager.Get(job.ID)
+	assert.False(t, ok)
+}
+
+func TestManager_GetUnknown(t *testing.T) {
+	manager := NewManager(&stubAnalyzer{}, 1, 10)
+
+	_, ok := manager.Get("missing")
+	assert.False(t, ok)
+}
@@ -0,0 +1,167 @@
+package netguard
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// blockedNetworks lists ranges that are not covered by the net.IP classification helpers
+// but must never be reached from a public-facing fetcher.
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // "this" network
+	"100.64.0.0/10", // carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"240.0.0.0/4",   // reserved
+)
+
+// BlockedError is returned when a connection would reach an address the Guard does not allow.
+type BlockedError struct {
+	Address string
+}
+
+func (e *BlockedError) Error() string {
+	return "refusing to connect to " + e.Address + ": private, loopback, link-local or metadata address"
+}
+
+// Guard blocks outgoing connections to private, loopback, link-local and cloud metadata addresses.
+// The check runs on the resolved IP right before connecting, so it also covers hostnames that
+// resolve to internal addresses and every hop of a redirect.
+type Guard struct {
+	allowedHosts    map[string]bool
+	allowedNetworks []*net.IPNet
+	dialer          net.Dialer
+}
+
+// NewGuard creates a Guard with an allowlist of trusted internal hosts.
+// Each entry is a hostname, an IP address, or a CIDR range.
+func NewGuard(allowlist []string) (*Guard, error) {
+	guard := &Guard{
+		allowedHosts: make(map[string]bool),
+		dialer:       net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second},
+	}
+
+	for _, entry := range allowlist {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case strings.Contains(entry, "/"):
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allowlist entry %q: %w", entry, err)
+			}
+			guard.allowedNetworks = append(guard.allowedNetworks, network)
+		case net.ParseIP(entry) != nil:
+			ip := net.ParseIP(entry)
+			if v4 := ip.To4(); v4 != nil {
+				ip = v4
+			}
+			guard.allowedNetworks = append(guard.allowedNetworks, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+		default:
+			guard.allowedHosts[entry] = true
+		}
+	}
+	guard.dialer.Control = guard.control
+
+	return guard, nil
+}
+
+// ParseAllowlist splits a comma-separated allowlist, such as the ALLOWED_HOSTS environment variable.
+func ParseAllowlist(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// DialContext connects like net.Dialer.DialContext but refuses blocked addresses.
+// Allowlisted hostnames bypass the address check entirely.
+func (guard *Guard) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if guard.allowedHosts[strings.ToLower(strings.TrimSuffix(host, "."))] {
+		unguarded := guard.dialer
+		unguarded.Control = nil
+		return unguarded.DialContext(ctx, network, address)
+	}
+
+	return guard.dialer.DialContext(ctx, network, address)
+}
+
+// IsAllowed reports whether the guard permits connections to ip.
+func (guard *Guard) IsAllowed(ip net.IP) bool {
+	for _, network := range guard.allowedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return !isBlocked(ip)
+}
+
+// control runs on the resolved address of every connection attempt.
+func (guard *Guard) control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !guard.IsAllowed(ip) {
+		return &BlockedError{Address: address}
+	}
+	return nil
+}
+
+// NewHTTPClient returns an HTTP client whose every connection, including redirects, goes through the guard.
+// Proxies are disabled because they would connect on the client's behalf and bypass the check.
+func NewHTTPClient(guard *Guard, timeout time.Duration) *http.Client {
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           guard.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+func isBlocked(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,107 @@
+package netguard
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuard_IsAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		ip      string
+		allowed bool
+	}{
+		{"public IPv4", "93.184.216.34", true},
+		{"public IPv6", "2606:2800:220:1:248:1893:25c8:1946", true},
+		{"loopback", "127.0.0.1", false},
+		{"loopback IPv6", "::1", false},
+		{"IPv4-mapped loopback", "::ffff:127.0.0.1", false},
+		{"RFC1918 10/8", "10.1.2.3", false},
+		{"RFC1918 172.16/12", "172.20.0.1", false},
+		{"RFC1918 192.168/16", "192.168.1.1", false},
+		{"cloud metadata", "169.254.169.254", false},
+		{"IPv6 link-local", "fe80::1", false},
+		{"IPv6 unique local", "fd00::1", false},
+		{"unspecified", "0.0.0.0", false},
+		{"carrier-grade NAT", "100.64.0.1", false},
+	}
+
+	guard, err := NewGuard(nil)
+	assert.NoError(t, err)
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.allowed, guard.IsAllowed(net.ParseIP(testCase.ip)))
+		})
+	}
+}
+
+func TestGuard_Allowlist(t *testing.T) {
+	guard, err := NewGuard([]string{"10.0.0.0/8", "192.168.1.10", "intranet.local"})
+	assert.NoError(t, err)
+
+	assert.True(t, guard.IsAllowed(net.ParseIP("10.20.30.40")))
+	assert.True(t, guard.IsAllowed(net.ParseIP("192.168.1.10")))
+	assert.False(t, guard.IsAllowed(net.ParseIP("192.168.1.11")))
+	assert.True(t, guard.allowedHosts["intranet.local"])
+
+	_, err = NewGuard([]string{"10.0.0.0/99"})
+	assert.Error(t, err)
+}
+
+func TestParseAllowlist(t *testing.T) {
+	assert.Equal(t, []string{"localhost", "10.0.0.0/8"}, ParseAllowlist(" localhost, ,10.0.0.0/8 "))
+	assert.Nil(t, ParseAllowlist(""))
+}
+
+func TestNewHTTPClient_BlocksLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	guard, err := NewGuard(nil)
+	assert.NoError(t, err)
+	client := NewHTTPClient(guard, time.Second)
+
+	_, err = client.Get(server.URL)
+
+	var blockedErr *BlockedError
+	assert.True(t, errors.As(err, &blockedErr), "expected BlockedError, got %v", err)
+}
+
+func TestNewHTTPClient_AllowlistedHostStillGuardsRedirects(t *testing.T) {
+	var target string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, target, http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	_, port, _ := net.SplitHostPort(serverURL.Host)
+	// The redirect goes to the raw IP, which the hostname allowlist does not cover
+	target = server.URL + "/final"
+
+	guard, err := NewGuard([]string{"localhost"})
+	assert.NoError(t, err)
+	client := NewHTTPClient(guard, time.Second)
+
+	resp, err := client.Get("http://localhost:" + port + "/final")
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+
+	_, err = client.Get("http://localhost:" + port + "/redirect")
+	var blockedErr *BlockedError
+	assert.True(t, errors.As(err, &blockedErr), "expected BlockedError, got %v", err)
+}
@@ -0,0 +1,187 @@
+package robots
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxSize is the number of bytes of a robots.txt file that are parsed; the rest is ignored, as RFC 9309 allows.
+const MaxSize = 500 << 10
+
+// Rules are the groups of a parsed robots.txt file. A nil *Rules allows everything, which is how
+// a missing robots.txt is treated.
+type Rules struct {
+	groups   []group
+	sitemaps []string
+}
+
+// group holds the rules that apply to the user agents named at its start.
+type group struct {
+	agents     []string
+	rules      []rule
+	crawlDelay time.Duration
+}
+
+type rule struct {
+	allow   bool
+	pattern string
+}
+
+// Parse reads a robots.txt file. Lines it does not understand are skipped, so it never fails.
+func Parse(reader io.Reader) *Rules {
+	rules := &Rules{}
+	var current *group
+	// A user-agent line that follows rules starts a new group; consecutive ones share a group
+	startsGroup := true
+
+	scanner := bufio.NewScanner(io.LimitReader(reader, MaxSize))
+	scanner.Buffer(make([]byte, 0, 64<<10), MaxSize)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if startsGroup || current == nil {
+				rules.groups = append(rules.groups, group{})
+				current = &rules.groups[len(rules.groups)-1]
+				startsGroup = false
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			startsGroup = true
+			// An empty Disallow allows everything, which is the default anyway
+			if current != nil && value != "" {
+				current.rules = append(current.rules, rule{allow: key == "allow", pattern: value})
+			}
+		case "sitemap":
+			// Sitemap lines belong to no group, so they neither start nor end one
+			if value != "" {
+				rules.sitemaps = append(rules.sitemaps, value)
+			}
+		case "crawl-delay":
+			startsGroup = true
+			if seconds, err := strconv.ParseFloat(value, 64); current != nil && err == nil && seconds >= 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+	}
+	return rules
+}
+
+// Allowed reports whether userAgent may request rawURL. The most specific matching rule wins,
+// and Allow wins over Disallow when they are equally specific. /robots.txt itself is always allowed.
+func (rules *Rules) Allowed(userAgent, rawURL string) bool {
+	if rules == nil {
+		return true
+	}
+	path := "/"
+	if parsed, err := url.Parse(rawURL); err == nil {
+		path = parsed.EscapedPath()
+		if path == "" {
+			path = "/"
+		}
+		if parsed.RawQuery != "" {
+			path += "?" + parsed.RawQuery
+		}
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+
+	allowed, longest := true, -1
+	for _, group := range rules.match(userAgent) {
+		for _, rule := range group.rules {
+			if !matches(rule.pattern, path) {
+				continue
+			}
+			if length := len(rule.pattern); length > longest || (length == longest && rule.allow) {
+				allowed, longest = rule.allow, length
+			}
+		}
+	}
+	return allowed
+}
+
+// CrawlDelay returns how long userAgent is asked to wait between requests, or zero if it is not.
+func (rules *Rules) CrawlDelay(userAgent string) time.Duration {
+	if rules == nil {
+		return 0
+	}
+	var delay time.Duration
+	for _, group := range rules.match(userAgent) {
+		delay = max(delay, group.crawlDelay)
+	}
+	return delay
+}
+
+// Sitemaps returns the sitemap URLs the file lists, in order.
+func (rules *Rules) Sitemaps() []string {
+	if rules == nil {
+		return nil
+	}
+	return rules.sitemaps
+}
+
+// match returns the groups naming the product token of userAgent or, if there are none, the groups for "*".
+func (rules *Rules) match(userAgent string) []group {
+	token := strings.ToLower(ProductToken(userAgent))
+	var named, wildcard []group
+	for _, group := range rules.groups {
+		switch {
+		case token != "" && slices.Contains(group.agents, token):
+			named = append(named, group)
+		case slices.Contains(group.agents, "*"):
+			wildcard = append(wildcard, group)
+		}
+	}
+	if len(named) > 0 {
+		return named
+	}
+	return wildcard
+}
+
+// ProductToken returns the name a User-Agent header identifies its crawler by, such as "Googlebot"
+// for "Googlebot/2.1 (+http://www.google.com/bot.html)".
+func ProductToken(userAgent string) string {
+	token, _, _ := strings.Cut(strings.TrimSpace(userAgent), " ")
+	token, _, _ = strings.Cut(token, "/")
+	return token
+}
+
+// matches reports whether path matches a rule pattern, where "*" matches any run of characters
+// and a trailing "$" anchors the pattern to the end of the path.
+func matches(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	parts := strings.Split(strings.TrimSuffix(pattern, "$"), "*")
+
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	if len(parts) == 1 {
+		return !anchored || rest == ""
+	}
+	for _, part := range parts[1 : len(parts)-1] {
+		index := strings.Index(rest, part)
+		if index < 0 {
+			return false
+		}
+		rest = rest[index+len(part):]
+	}
+	last := parts[len(parts)-1]
+	if anchored {
+		return strings.HasSuffix(rest, last)
+	}
+	return strings.Contains(rest, last)
+}
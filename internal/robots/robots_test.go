@@ -0,0 +1,93 @@
+package robots
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const robotsFile = `# Example robots.txt
+User-agent: *
+Disallow: /private/
+Allow: /private/press/
+Disallow: /*.pdf$
+Disallow: /search?q=*
+Crawl-delay: 2
+
+User-agent: Googlebot
+User-agent: Bingbot
+Disallow: /no-google
+Allow: /
+
+User-agent: GPTBot
+Disallow: /
+
+Sitemap: https://example.com/sitemap.xml
+`
+
+func TestRules_Allowed(t *testing.T) {
+	rules := Parse(strings.NewReader(robotsFile))
+
+	tests := []struct {
+		name      string
+		userAgent string
+		url       string
+		allowed   bool
+	}{
+		{"unlisted path", "gogeturl/1.0", "https://example.com/about", true},
+		{"disallowed directory", "gogeturl/1.0", "https://example.com/private/keys", false},
+		{"longer allow wins", "gogeturl/1.0", "https://example.com/private/press/release", true},
+		{"anchored wildcard", "gogeturl/1.0", "https://example.com/files/report.pdf", false},
+		{"anchor stops at the end", "gogeturl/1.0", "https://example.com/files/report.pdf.html", true},
+		{"query", "gogeturl/1.0", "https://example.com/search?q=go", false},
+		{"robots.txt itself", "GPTBot", "https://example.com/robots.txt", true},
+		{"named group replaces the wildcard", "Googlebot/2.1 (+http://www.google.com/bot.html)", "https://example.com/private/keys", true},
+		{"named group", "Googlebot/2.1", "https://example.com/no-google", false},
+		{"second agent of a group", "bingbot", "https://example.com/no-google", false},
+		{"everything disallowed", "GPTBot/1.0", "https://example.com/", false},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.allowed, rules.Allowed(testCase.userAgent, testCase.url))
+		})
+	}
+}
+
+func TestRules_CrawlDelay(t *testing.T) {
+	rules := Parse(strings.NewReader(robotsFile))
+
+	assert.Equal(t, 2*time.Second, rules.CrawlDelay("gogeturl"))
+	assert.Zero(t, rules.CrawlDelay("Googlebot"))
+}
+
+func TestRules_Sitemaps(t *testing.T) {
+	rules := Parse(strings.NewReader(robotsFile + "Sitemap: https://example.com/news.xml.gz\nSitemap:\n"))
+
+	assert.Equal(t, []string{"https://example.com/sitemap.xml", "https://example.com/news.xml.gz"}, rules.Sitemaps())
+	// A Sitemap line between rules does not split their group
+	assert.False(t, Parse(strings.NewReader("User-agent: *\nSitemap: /s.xml\nDisallow: /a")).Allowed("gogeturl", "https://example.com/a"))
+}
+
+func TestRules_Nil(t *testing.T) {
+	var rules *Rules
+
+	assert.True(t, rules.Allowed("gogeturl", "https://example.com/private"))
+	assert.Zero(t, rules.CrawlDelay("gogeturl"))
+	assert.Empty(t, rules.Sitemaps())
+}
+
+func TestParse_IgnoresRulesOutsideGroups(t *testing.T) {
+	rules := Parse(strings.NewReader("Disallow: /\nnonsense\nUser-agent: *\nDisallow: /admin"))
+
+	assert.True(t, rules.Allowed("gogeturl", "https://example.com/"))
+	assert.False(t, rules.Allowed("gogeturl", "https://example.com/admin/users"))
+}
+
+func TestProductToken(t *testing.T) {
+	assert.Equal(t, "Googlebot", ProductToken("Googlebot/2.1 (+http://www.google.com/bot.html)"))
+	assert.Equal(t, "gogeturl", ProductToken("gogeturl"))
+	assert.Equal(t, "", ProductToken(""))
+}
@@ -0,0 +1,81 @@
+package sitemap
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxSize is the largest uncompressed sitemap the sitemaps protocol allows; anything beyond is not read.
+const MaxSize = 50 << 20
+
+// Entry is one page listed in a sitemap.
+type Entry struct {
+	URL          string `json:"url"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// Sitemap is a parsed sitemap file: either an index of other sitemaps or a set of pages.
+type Sitemap struct {
+	// Index is set for a <sitemapindex>, whose Sitemaps are the files it lists.
+	Index    bool
+	Sitemaps []string
+	URLs     []Entry
+}
+
+// document matches both root elements, whatever namespace they declare.
+type document struct {
+	XMLName xml.Name
+	URLs    []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// Parse reads a <urlset> or <sitemapindex>, decompressing it first if it is gzipped.
+// Entries without a location are skipped.
+func Parse(reader io.Reader) (*Sitemap, error) {
+	buffered := bufio.NewReader(reader)
+	if magic, _ := buffered.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		unzipped, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzipped sitemap: %w", err)
+		}
+		defer unzipped.Close()
+		reader = unzipped
+	} else {
+		reader = buffered
+	}
+
+	var doc document
+	if err := xml.NewDecoder(io.LimitReader(reader, MaxSize)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid sitemap: %w", err)
+	}
+
+	sitemap := &Sitemap{}
+	switch doc.XMLName.Local {
+	case "urlset":
+		for _, url := range doc.URLs {
+			if loc := strings.TrimSpace(url.Loc); loc != "" {
+				sitemap.URLs = append(sitemap.URLs, Entry{URL: loc, LastModified: strings.TrimSpace(url.LastMod)})
+			}
+		}
+	case "sitemapindex":
+		sitemap.Index = true
+		for _, child := range doc.Sitemaps {
+			if loc := strings.TrimSpace(child.Loc); loc != "" {
+				sitemap.Sitemaps = append(sitemap.Sitemaps, loc)
+			}
+		}
+	default:
+		return nil, errors.New("invalid sitemap: expected <urlset> or <sitemapindex>, got <" + doc.XMLName.Local + ">")
+	}
+	return sitemap, nil
+}
@@ -0,0 +1,60 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const urlset = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc> https://example.com/ </loc><lastmod>2025-01-01</lastmod></url>
+  <url><loc>https://example.com/about</loc></url>
+  <url><lastmod>2025-01-01</lastmod></url>
+</urlset>`
+
+func TestParse_URLSet(t *testing.T) {
+	sitemap, err := Parse(strings.NewReader(urlset))
+
+	require.NoError(t, err)
+	assert.False(t, sitemap.Index)
+	assert.Equal(t, []Entry{
+		{URL: "https://example.com/", LastModified: "2025-01-01"},
+		{URL: "https://example.com/about"},
+	}, sitemap.URLs)
+}
+
+func TestParse_Index(t *testing.T) {
+	sitemap, err := Parse(strings.NewReader(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+		<sitemap><loc>https://example.com/pages.xml</loc></sitemap>
+		<sitemap><loc>https://example.com/posts.xml.gz</loc><lastmod>2025-01-01</lastmod></sitemap>
+	</sitemapindex>`))
+
+	require.NoError(t, err)
+	assert.True(t, sitemap.Index)
+	assert.Equal(t, []string{"https://example.com/pages.xml", "https://example.com/posts.xml.gz"}, sitemap.Sitemaps)
+	assert.Empty(t, sitemap.URLs)
+}
+
+func TestParse_Gzip(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, _ = writer.Write([]byte(urlset))
+	require.NoError(t, writer.Close())
+
+	sitemap, err := Parse(&compressed)
+
+	require.NoError(t, err)
+	assert.Len(t, sitemap.URLs, 2)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, body := range []string{"", "<html><body>Not found</body></html>", "<urlset><url>", "\x1f\x8bnot gzip"} {
+		_, err := Parse(strings.NewReader(body))
+		assert.Error(t, err, body)
+	}
+}
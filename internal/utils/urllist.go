@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// ParseURLList reads one URL per line, skipping blank lines, "#" comments and duplicates.
+// Spaces and tabs also separate URLs so pasted lists work as well; commas do not, since
+// they may be part of a URL.
+func ParseURLList(reader io.Reader) ([]string, error) {
+	var urls []string
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, strings.Fields(line)...)
+	}
+
+	return UniqueURLs(urls), scanner.Err()
+}
+
+// UniqueURLs trims the given URLs and drops empty ones and duplicates, keeping their order.
+func UniqueURLs(urls []string) []string {
+	var unique []string
+	seen := make(map[string]bool)
+	for _, rawURL := range urls {
+		rawURL = strings.TrimSpace(rawURL)
+		if rawURL != "" && !seen[rawURL] {
+			seen[rawURL] = true
+			unique = append(unique, rawURL)
+		}
+	}
+	return unique
+}
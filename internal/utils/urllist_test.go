@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseURLList(t *testing.T) {
+	input := `
+# landing pages
+https://example.com
+https://example.com/pricing https://example.com/about
+https://example.com/search?ids=1,2
+
+https://example.com
+`
+
+	urls, err := ParseURLList(strings.NewReader(input))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"https://example.com",
+		"https://example.com/pricing",
+		"https://example.com/about",
+		"https://example.com/search?ids=1,2",
+	}, urls)
+}
+
+func TestUniqueURLs(t *testing.T) {
+	urls := UniqueURLs([]string{" https://example.com/?ids=1,2 ", "", "https://example.com/?ids=1,2", "https://example.com"})
+
+	assert.Equal(t, []string{"https://example.com/?ids=1,2", "https://example.com"}, urls)
+}
@@ -0,0 +1,33 @@
+// Makes every table with the "sortable" class sortable by clicking its column headers.
+// Cells may provide a data-sort-value attribute; headers marked data-sort="number" sort numerically.
+document.addEventListener("DOMContentLoaded", function () {
+    document.querySelectorAll("table.sortable").forEach(function (table) {
+        table.querySelectorAll("th").forEach(function (header, column) {
+            header.addEventListener("click", function () {
+                var ascending = header.getAttribute("aria-sort") !== "ascending";
+                var numeric = header.dataset.sort === "number";
+                var body = table.tBodies[0];
+                var rows = Array.prototype.slice.call(body.rows);
+
+                rows.sort(function (a, b) {
+                    var left = sortValue(a.cells[column]);
+                    var right = sortValue(b.cells[column]);
+                    var result = numeric ? Number(left) - Number(right) : left.localeCompare(right);
+                    return ascending ? result : -result;
+                });
+
+                table.querySelectorAll("th").forEach(function (other) {
+                    other.removeAttribute("aria-sort");
+                });
+                header.setAttribute("aria-sort", ascending ? "ascending" : "descending");
+                rows.forEach(function (row) {
+                    body.appendChild(row);
+                });
+            });
+        });
+    });
+
+    function sortValue(cell) {
+        return cell.dataset.sortValue !== undefined ? cell.dataset.sortValue : cell.textContent.trim();
+    }
+});